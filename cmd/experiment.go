@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/99109766/fms-scheduler/internal/experiment"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+)
+
+// runExperiment runs the utilization sweep and writes the acceptance ratios as
+// CSV and JSON.
+func runExperiment(args []string) {
+	flags := flag.NewFlagSet("experiment", flag.ExitOnError)
+	configPath := flags.String("config", "", "Path to the configuration file (YAML format)")
+	csvPath := flags.String("csv", "experiment.csv", "Path of the acceptance ratio table to write")
+	jsonPath := flags.String("json", "experiment.json", "Path of the acceptance ratios to write as JSON")
+	seed := flags.Int64("seed", 0, seedUsage)
+	flags.Parse(args)
+
+	cfg := loadConfig(*configPath, *seed)
+	if cfg.ModeReturn == scheduler.ReturnHyperperiod {
+		log.Fatalf("mode_return %q needs commensurable task periods, which generated task sets do not have", scheduler.ReturnHyperperiod)
+	}
+
+	fmt.Printf("=== Running Experiment (seed %d) ===\n", cfg.Seed)
+	report := experiment.Run(cfg)
+	for _, row := range report.Rows {
+		fmt.Println(row)
+	}
+
+	file, err := os.Create(*csvPath)
+	if err != nil {
+		log.Fatalf("Error creating experiment file: %v", err)
+	}
+	defer file.Close()
+	if err := experiment.WriteCSV(file, report); err != nil {
+		log.Fatalf("Error writing experiment file: %v", err)
+	}
+
+	if err := writeJSON(*jsonPath, report); err != nil {
+		log.Fatalf("Error writing experiment file: %v", err)
+	}
+
+	fmt.Printf("\n=== Experiment written to %s and %s ===\n", *csvPath, *jsonPath)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/99109766/fms-scheduler/config"
+)
+
+// seedUsage is the usage of the --seed flag of the commands drawing random numbers.
+const seedUsage = "Seed of the random sources, overriding the seed of the configuration file (default: the configured seed, or one taken from the clock)"
+
+// loadConfig loads the configuration file, exiting if it is missing or invalid.
+// A non-zero seed overrides the configured one.
+func loadConfig(path string, seed int64) *config.Config {
+	if path == "" {
+		log.Fatal("Config file path must be provided using the --config flag")
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+	if seed != 0 {
+		cfg.Seed = seed
+	}
+	return cfg
+}
+
+// writeJSON writes v as indented JSON to the file.
+func writeJSON(path string, v any) error {
+	encoded, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, encoded, 0o644)
+}
+
+// readJSON decodes the JSON file into v.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// parseIDs parses a comma separated list of IDs.
+func parseIDs(list string) ([]int, error) {
+	var ids []int
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+
+	"github.com/99109766/fms-scheduler/internal/resources"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// runGenerate generates a random task set step by step, printing the outcome of
+// every step, and writes it to a task set file.
+func runGenerate(args []string) {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	configPath := flags.String("config", "", "Path to the configuration file (YAML format)")
+	outPath := flags.String("out", "taskset.json", "Path of the task set file to write")
+	seed := flags.Int64("seed", 0, seedUsage)
+	flags.Parse(args)
+
+	cfg := loadConfig(*configPath, *seed)
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	fmt.Printf("=== Seed %d ===\n\n", cfg.Seed)
+
+	// Generate tasks using UUnifast without any resource assignments
+	taskSet := tasks.GenerateTasksUUnifast(cfg, rng)
+
+	fmt.Println("=== Generated Tasks ===")
+	for _, t := range taskSet {
+		fmt.Println(t)
+	}
+
+	resourceList := resources.GenerateResources(cfg, rng)
+	tasks.AssignResourcesToTasks(cfg, rng, taskSet, resourceList)
+
+	fmt.Println("\n=== Resource Assignments ===")
+	for _, r := range resourceList {
+		fmt.Printf("Resource %d (%d units) assigned to tasks: %v\n", r.ID, r.Units, r.AssignedTasks)
+	}
+
+	tasks.AssignCriticalSections(cfg, rng, taskSet, resourceList)
+
+	fmt.Println("\n=== Tasks and Assigned Critical Sections ===")
+	for _, t := range taskSet {
+		fmt.Printf("Task %d (Criticality: %v) Critical Sections:\n", t.ID, t.Criticality)
+		for _, op := range t.LockSequence() {
+			fmt.Printf("  %s%v\n", strings.Repeat("  ", op.Depth-1), op)
+		}
+	}
+
+	tasks.DeterminePriorityLevels(taskSet)
+	tasks.ComputePreemptionLevels(cfg, taskSet, resourceList)
+	tasks.ComputeBlockingTimes(taskSet, resourceList)
+
+	fmt.Println("\n=== Resources with Ceilings ===")
+	for _, r := range resourceList {
+		fmt.Printf("Resource %d: Units = %d, Ceiling = %d, Ceilings by Available Units = %v, Assigned Tasks = %v\n",
+			r.ID, r.Units, r.Ceiling, r.Ceilings, r.AssignedTasks)
+	}
+
+	fmt.Println("\n=== Tasks with Preemption Levels ===")
+	for _, t := range taskSet {
+		fmt.Printf("Task %d: Base Priority = %d, Preemption Level = %d, Blocking = %.3f\n", t.ID, t.Priority, t.PreemptionLevel, t.Blocking)
+	}
+
+	if err := tasks.SaveTaskSet(*outPath, cfg.Seed, taskSet, resourceList); err != nil {
+		log.Fatalf("Error writing task set file: %v", err)
+	}
+	fmt.Printf("\n=== Task set written to %s ===\n", *outPath)
+}
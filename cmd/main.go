@@ -1,115 +1,50 @@
 package main
 
 import (
-	"encoding/json"
-	"flag"
 	"fmt"
-	"log"
 	"os"
-
-	"github.com/99109766/fms-scheduler/config"
-	"github.com/99109766/fms-scheduler/internal/resources"
-	"github.com/99109766/fms-scheduler/internal/scheduler"
-	"github.com/99109766/fms-scheduler/internal/tasks"
 )
 
-func main() {
-	// Parse flags
-	configPathPtr := flag.String("config", "", "Path to the configuration file (YAML format)")
-	flag.Parse()
-
-	if configPathPtr == nil || *configPathPtr == "" {
-		log.Fatal("Config file path must be provided using --config or -c flag")
-	}
-	configPath := *configPathPtr
-
-	// Load Configuration
-	cfg, err := config.LoadConfig(configPath)
-	if err != nil {
-		log.Fatalf("Error loading configuration: %v", err)
-	}
-
-	// Generate tasks using UUnifast without any resource assignments
-	taskSet := tasks.GenerateTasksUUnifast(cfg)
-
-	fmt.Println("=== Generated Tasks ===")
-	for _, t := range taskSet {
-		fmt.Println(t)
-	}
+// command is a subcommand of the CLI.
+type command struct {
+	name        string
+	description string
+	run         func(args []string)
+}
 
-	resourceList := resources.GenerateResources(cfg.NumResources)
-	tasks.AssignResourcesToTasks(cfg, taskSet, resourceList)
+var commands = []command{
+	{"generate", "Generate a random task set with its resources and write it to a file", runGenerate},
+	{"analyze", "Run the schedulability tests on a task set file", runAnalyze},
+	{"simulate", "Simulate the scheduling of a task set file", runSimulate},
+	{"experiment", "Run the utilization sweep of the experiment config section", runExperiment},
+	{"plot", "Render a simulated schedule as an SVG or HTML Gantt chart", runPlot},
+	{"validate", "Check a simulated schedule against the scheduling invariants", runValidate},
+}
 
-	fmt.Println("\n=== Resource Assignments ===")
-	for _, r := range resourceList {
-		fmt.Printf("Resource %d assigned to tasks: %v\n", r.ID, r.AssignedTasks)
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
 	}
 
-	tasks.AssignCriticalSections(cfg, taskSet, resourceList)
-
-	fmt.Println("\n=== Tasks and Assigned Critical Sections ===")
-	for _, t := range taskSet {
-		fmt.Printf("Task %d (Criticality: %v) Critical Sections:\n", t.ID, t.Criticality)
-		for _, cs := range t.CriticalSections {
-			fmt.Printf("  - Resource %d: Start=%.2f, Duration=%.2f, End=%.2f\n",
-				cs.ResourceID, cs.Start, cs.Duration, cs.Start+cs.Duration)
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			c.run(os.Args[2:])
+			return
 		}
 	}
 
-	tasks.DeterminePriorityLevels(taskSet)
-	tasks.ComputeResourceCeilings(taskSet, resourceList)
-	tasks.AssignPreemptionLevels(taskSet, resourceList)
-
-	fmt.Println("\n=== Resources with Ceilings ===")
-	for _, r := range resourceList {
-		fmt.Printf("Resource %d: Ceiling = %d, Assigned Tasks = %v\n", r.ID, r.Ceiling, r.AssignedTasks)
+	if os.Args[1] != "help" && os.Args[1] != "-h" && os.Args[1] != "--help" {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", os.Args[1])
 	}
+	usage()
+	os.Exit(2)
+}
 
-	fmt.Println("\n=== Tasks with Preemption Levels ===")
-	for _, t := range taskSet {
-		fmt.Printf("Task %d: Base Priority = %d, Preemption Level = %d\n", t.ID, t.Priority, t.PreemptionLevel)
-	}
-
-	fmt.Println("\n=== Running Scheduler Simulation ===")
-	schedule, err := scheduler.RunScheduler(taskSet, cfg.SimulateTime)
-	if err != nil {
-		log.Fatalf("Error running scheduler: %v", err)
-	}
-
-	fmt.Println("\n=== Final Scheduler ===")
-
-	file, err := os.Create("schedule.json")
-	if err != nil {
-		log.Fatalf("Error creating schedule file: %v", err)
-	}
-	defer file.Close()
-	encoded, err := json.MarshalIndent(schedule, "", "  ")
-	if err != nil {
-		log.Fatalf("Error encoding schedule: %v", err)
-	}
-	fmt.Println(string(encoded))
-	_, err = file.Write(encoded)
-	if err != nil {
-		log.Fatalf("Error writing schedule file: %v", err)
-	}
-
-	fmt.Println("\n=== Schedule written to schedule.json ===")
-
-	file, err = os.Create("tasks.json")
-	if err != nil {
-		log.Fatalf("Error creating tasks file: %v", err)
-	}
-	defer file.Close()
-	encoded, err = json.MarshalIndent(taskSet, "", "  ")
-	if err != nil {
-		log.Fatalf("Error encoding tasks: %v", err)
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-11s %s\n", c.name, c.description)
 	}
-	_, err = file.Write(encoded)
-	if err != nil {
-		log.Fatalf("Error writing tasks file: %v", err)
-	}
-
-	fmt.Println("\n=== Tasks written to tasks.json ===")
-
-	fmt.Println("\n=== Done ===")
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for the flags of a command.\n", os.Args[0])
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/99109766/fms-scheduler/internal/gantt"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// runPlot renders a simulated schedule as a Gantt chart.
+func runPlot(args []string) {
+	flags := flag.NewFlagSet("plot", flag.ExitOnError)
+	schedulePath := flags.String("schedule", "schedule.json", "Path to the simulation result")
+	taskSetPath := flags.String("taskset", "taskset.json", "Path to the task set file, or builtin:<name>")
+	outPath := flags.String("out", "schedule.svg", "Path of the chart, rendered as HTML if it ends in .html and as SVG otherwise")
+	resourceLanes := flags.Bool("resource-lanes", false, "Add one lane per resource")
+	width := flags.Int("width", 1600, "Width of the chart in pixels")
+	title := flags.String("title", "Schedule", "Title of the chart")
+	flags.Parse(args)
+
+	var result scheduler.Result
+	if err := readJSON(*schedulePath, &result); err != nil {
+		log.Fatalf("Error loading schedule: %v", err)
+	}
+	taskSetFile, err := tasks.LoadTaskSet(*taskSetPath, nil)
+	if err != nil {
+		log.Fatalf("Error loading task set: %v", err)
+	}
+
+	file, err := os.Create(*outPath)
+	if err != nil {
+		log.Fatalf("Error creating chart file: %v", err)
+	}
+	defer file.Close()
+
+	opts := gantt.Options{Title: *title, Width: *width, ResourceLanes: *resourceLanes}
+	switch strings.ToLower(filepath.Ext(*outPath)) {
+	case ".html", ".htm":
+		err = gantt.RenderHTML(file, &result, taskSetFile.Tasks, opts)
+	default:
+		err = gantt.Render(file, &result, taskSetFile.Tasks, opts)
+	}
+	if err != nil {
+		log.Fatalf("Error writing chart file: %v", err)
+	}
+
+	fmt.Printf("=== Chart written to %s ===\n", *outPath)
+}
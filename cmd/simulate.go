@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/99109766/fms-scheduler/internal/analysis"
+	"github.com/99109766/fms-scheduler/internal/metrics"
+	"github.com/99109766/fms-scheduler/internal/scenario"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+	"github.com/99109766/fms-scheduler/internal/trace"
+)
+
+// runSimulate simulates the scheduling of a task set file and writes the
+// schedule and its timing metrics.
+func runSimulate(args []string) {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	configPath := flags.String("config", "", "Path to the configuration file (YAML format)")
+	taskSetPath := flags.String("taskset", "taskset.json", "Path to the task set file, or builtin:<name>")
+	scenarioPath := flags.String("scenario", "", "Path to a scenario file (YAML or JSON) of events to inject into the simulation")
+	tracePath := flags.String("trace", "", "Path of a JSON Lines file receiving the simulation events")
+	traceKinds := flags.String("trace-kinds", "", "Comma separated event kinds to trace (default: all)")
+	traceTasks := flags.String("trace-tasks", "", "Comma separated task IDs to trace (default: all)")
+	outPath := flags.String("out", "schedule.json", "Path of the simulation result to write")
+	metricsPath := flags.String("metrics", "metrics.json", "Path of the timing metrics to write")
+	metricsTablePath := flags.String("metrics-table", "metrics.txt", "Path of the timing metrics table to write")
+	seed := flags.Int64("seed", 0, seedUsage)
+	flags.Parse(args)
+
+	cfg := loadConfig(*configPath, *seed)
+	taskSetFile, err := tasks.LoadTaskSet(*taskSetPath, cfg)
+	if err != nil {
+		log.Fatalf("Error loading task set: %v", err)
+	}
+
+	if protocol, err := scheduler.NewProtocol(cfg.Protocol); err == nil && !protocol.PreventsDeadlock() {
+		if cycles := analysis.LockCycles(taskSetFile.Tasks, taskSetFile.Resources); len(cycles) > 0 {
+			fmt.Printf("=== Warning: %d lock-order cycle(s) can deadlock under the %s protocol ===\n", len(cycles), cfg.Protocol)
+			for _, c := range cycles {
+				fmt.Println(c)
+			}
+		}
+	}
+
+	var opts []scheduler.Option
+	if *scenarioPath != "" {
+		sc, err := scenario.LoadScenario(*scenarioPath)
+		if err != nil {
+			log.Fatalf("Error loading scenario: %v", err)
+		}
+		opts = append(opts, scheduler.WithScenario(sc))
+	}
+
+	kinds, err := trace.ParseKinds(*traceKinds)
+	if err != nil {
+		log.Fatalf("Error parsing trace kinds: %v", err)
+	}
+	taskIDs, err := parseIDs(*traceTasks)
+	if err != nil {
+		log.Fatalf("Error parsing trace tasks: %v", err)
+	}
+	opts = append(opts, scheduler.WithSink(trace.Filter(trace.NewConsoleSink(os.Stdout), kinds, taskIDs)))
+
+	var traceSink *trace.JSONLSink
+	if *tracePath != "" {
+		traceFile, err := os.Create(*tracePath)
+		if err != nil {
+			log.Fatalf("Error creating trace file: %v", err)
+		}
+		defer traceFile.Close()
+		traceSink = trace.NewJSONLSink(traceFile)
+		opts = append(opts, scheduler.WithSink(trace.Filter(traceSink, kinds, taskIDs)))
+	}
+
+	fmt.Printf("=== Running Scheduler Simulation (seed %d) ===\n", cfg.Seed)
+	result, err := scheduler.RunScheduler(cfg, taskSetFile.Tasks, taskSetFile.Resources, opts...)
+	if err != nil {
+		log.Fatalf("Error running scheduler: %v", err)
+	}
+	result.TaskSetSeed = taskSetFile.Seed
+	if traceSink != nil {
+		if err := traceSink.Err(); err != nil {
+			log.Fatalf("Error writing trace file: %v", err)
+		}
+		fmt.Printf("\n=== Trace written to %s ===\n", *tracePath)
+	}
+
+	if len(result.Misses) > 0 {
+		fmt.Printf("\n=== Deadline Misses (%d) ===\n", len(result.Misses))
+		for _, m := range result.Misses {
+			fmt.Printf("Job %d (Task %d, Criticality: %v) missed Deadline=%.3f in %v mode: %s, Lateness=%.3f\n",
+				m.JobID, m.TaskID, m.Criticality, m.Deadline, m.Mode, m.Outcome, m.Lateness)
+		}
+	}
+
+	if len(result.Deadlocks) > 0 {
+		fmt.Printf("\n=== Deadlocks (%d) ===\n", len(result.Deadlocks))
+		for _, d := range result.Deadlocks {
+			fmt.Println(d)
+		}
+	}
+
+	if len(result.Injections) > 0 {
+		fmt.Println("\n=== Injected Events ===")
+		for _, r := range result.Injections {
+			fmt.Println(r)
+		}
+	}
+
+	if err := writeJSON(*outPath, result); err != nil {
+		log.Fatalf("Error writing schedule file: %v", err)
+	}
+	fmt.Printf("\n=== Schedule written to %s ===\n", *outPath)
+
+	fmt.Println("\n=== Timing Metrics ===")
+	metricsReport := metrics.Compute(result)
+	if err := metricsReport.WriteTable(os.Stdout); err != nil {
+		log.Fatalf("Error printing metrics: %v", err)
+	}
+
+	file, err := os.Create(*metricsTablePath)
+	if err != nil {
+		log.Fatalf("Error creating metrics file: %v", err)
+	}
+	defer file.Close()
+	if err := metricsReport.WriteTable(file); err != nil {
+		log.Fatalf("Error writing metrics file: %v", err)
+	}
+	if err := writeJSON(*metricsPath, metricsReport); err != nil {
+		log.Fatalf("Error writing metrics file: %v", err)
+	}
+	fmt.Printf("\n=== Metrics written to %s and %s ===\n", *metricsPath, *metricsTablePath)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/scenario"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+	"github.com/99109766/fms-scheduler/internal/validate"
+)
+
+// runValidate checks a simulated schedule against the scheduling invariants and
+// exits with status 1 if any of them is broken.
+func runValidate(args []string) {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	schedulePath := flags.String("schedule", "schedule.json", "Path to the simulation result to validate")
+	taskSetPath := flags.String("taskset", "taskset.json", "Path to the task set file, or builtin:<name>")
+	configPath := flags.String("config", "", "Configuration the schedule was simulated with (default: the zero configuration and the preemption levels of the task set file)")
+	scenarioPath := flags.String("scenario", "", "Scenario the schedule was simulated with, if any")
+	flags.Parse(args)
+
+	var result scheduler.Result
+	if err := readJSON(*schedulePath, &result); err != nil {
+		log.Fatalf("Error loading schedule: %v", err)
+	}
+
+	var cfg *config.Config
+	var opts []validate.Option
+	if *configPath != "" {
+		cfg = loadConfig(*configPath, 0)
+		opts = append(opts, validate.WithConfig(cfg))
+	} else if result.EarlyRelease {
+		log.Fatal("The schedule was simulated with early release: pass its configuration with the --config flag")
+	}
+	if *scenarioPath != "" {
+		sc, err := scenario.LoadScenario(*scenarioPath)
+		if err != nil {
+			log.Fatalf("Error loading scenario: %v", err)
+		}
+		opts = append(opts, validate.WithScenario(sc))
+	}
+
+	taskSetFile, err := tasks.LoadTaskSet(*taskSetPath, cfg)
+	if err != nil {
+		log.Fatalf("Error loading task set: %v", err)
+	}
+
+	violations := validate.Validate(&result, taskSetFile.Tasks, taskSetFile.Resources, opts...)
+	for _, v := range violations {
+		fmt.Println(v)
+	}
+	if len(violations) > 0 {
+		fmt.Printf("\n=== %d violations found ===\n", len(violations))
+		os.Exit(1)
+	}
+	fmt.Printf("=== Schedule is valid (%d jobs, %d intervals) ===\n", len(result.Jobs), len(result.Schedule))
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"reflect"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"gopkg.in/yaml.v3"
@@ -10,6 +11,7 @@ import (
 
 type Config struct {
 	NumResources  int        `yaml:"num_resources" validate:"min=0"`
+	ResourceUnits [2]int     `yaml:"resource_units" validate:"valid_range,dive,min=1"`
 	NumTasks      int        `yaml:"num_tasks" validate:"min=0"`
 	TotalUtility  float64    `yaml:"total_utility" validate:"min=0,max=1"`
 	PeriodRange   [2]float64 `yaml:"period_range" validate:"min=0,valid_range"`
@@ -19,7 +21,86 @@ type Config struct {
 	ResourceUsage [2]int     `yaml:"resource_usage" validate:"min=0,ltefield=NumResources,valid_range"`
 	CSFactor      float64    `yaml:"cs_factor" validate:"min=0,max=1"`
 	CSRange       [2]int     `yaml:"cs_range" validate:"min=0,valid_range"`
+	CSUnits       [2]int     `yaml:"cs_units" validate:"valid_range,dive,min=1"`
 	SimulateTime  float64    `yaml:"simulation_time" validate:"min=0"`
+
+	// Algorithm selects the scheduling algorithm: plain EDF ("edf", the default),
+	// EDF with virtual deadlines for HC tasks in Normal mode ("edf-vd"),
+	// deadline-monotonic ("dm") or rate-monotonic ("rm") fixed priorities, or
+	// fixed-priority Adaptive Mixed Criticality ("amc"), which suspends LC tasks
+	// in Overrun mode.
+	Algorithm string `yaml:"algorithm" validate:"omitempty,oneof=edf edf-vd dm rm amc"`
+
+	// PreemptionLevels selects how SRP preemption levels are derived: from relative
+	// deadlines ("deadline") or from rate-monotonic priorities ("rm"). By default
+	// they follow the priorities of the algorithm.
+	PreemptionLevels string `yaml:"preemption_levels" validate:"omitempty,oneof=deadline rm"`
+
+	// Protocol selects the resource access protocol: no protocol ("none"),
+	// non-preemptive critical sections ("npcs"), the Priority Inheritance Protocol
+	// ("pip"), the Priority Ceiling Protocol ("pcp") or the multi-unit Stack
+	// Resource Policy ("srp", the default).
+	Protocol string `yaml:"protocol" validate:"omitempty,oneof=none npcs pip pcp srp"`
+
+	// EarlyRelease keeps LC tasks running in Overrun mode (ER-EDF) at periods and
+	// deadlines extended by LCMaxPeriodRatio, releasing them early, down to their
+	// nominal period, whenever the slack reclaimed from HC jobs allows.
+	EarlyRelease     bool    `yaml:"early_release"`
+	LCMaxPeriodRatio float64 `yaml:"lc_max_period_ratio" validate:"omitempty,min=1"`
+
+	// ModeReturn selects when the system returns from Overrun to Normal mode: never
+	// (the default), at the first idle instant, one hyperperiod or ModeDwell time
+	// units after the switch. A return is deferred while an HC job is past its
+	// WCET1 budget. The hyperperiod return needs commensurable periods, such as
+	// the integer ones of the built-in task set: a simulation whose hyperperiod
+	// overflows is rejected, and so are experiments, whose generated periods are
+	// random.
+	ModeReturn string  `yaml:"mode_return" validate:"omitempty,oneof=never idle hyperperiod dwell"`
+	ModeDwell  float64 `yaml:"mode_dwell" validate:"min=0,required_if=ModeReturn dwell"`
+
+	ExecModel ExecModelConfig `yaml:"exec_model"`
+
+	// MissPolicy selects what happens to a job missing its deadline: it is aborted
+	// ("abort", the default), runs to completion ("continue"), or runs to
+	// completion while the next release of its task is skipped ("skip").
+	MissPolicy string `yaml:"miss_policy" validate:"omitempty,oneof=abort continue skip"`
+
+	Experiment ExperimentConfig `yaml:"experiment"`
+
+	// Seed seeds every random source: task set generation, execution times and
+	// experiments. LoadConfig replaces zero with a seed taken from the clock, so
+	// that every run has a seed to record and reproduce it with.
+	Seed int64 `yaml:"seed"`
+
+	// Quiet silences the simulation log. It is set by callers running many simulations.
+	Quiet bool `yaml:"-"`
+}
+
+// ExecModelConfig describes how the actual execution times of jobs are drawn.
+// Jobs execute a fraction of their WCET1: always all of it ("wcet", the default),
+// a uniform fraction in Range ("uniform") or a Beta(Alpha, Beta) fraction ("beta").
+// HC jobs overrun with OverrunProbability, or the per-task probability of
+// TaskOverrunProbability, executing WCET1 plus a fraction of WCET2. With "table",
+// the execution times of the first jobs of each task are listed explicitly in Table.
+type ExecModelConfig struct {
+	Kind                   string            `yaml:"kind" validate:"omitempty,oneof=wcet uniform beta table"`
+	Range                  [2]float64        `yaml:"range" validate:"valid_range,required_if=Kind uniform,dive,min=0,max=1"`
+	Alpha                  float64           `yaml:"alpha" validate:"min=0,required_if=Kind beta"`
+	Beta                   float64           `yaml:"beta" validate:"min=0,required_if=Kind beta"`
+	OverrunProbability     float64           `yaml:"overrun_probability" validate:"min=0,max=1"`
+	TaskOverrunProbability map[int]float64   `yaml:"task_overrun_probability" validate:"dive,min=0,max=1"`
+	Table                  map[int][]float64 `yaml:"table" validate:"dive,dive,min=0"`
+}
+
+// ExperimentConfig describes a schedulability experiment: for every total
+// utilization, TaskSets random task sets are generated, analysed and simulated,
+// under each combination of Algorithms and Protocols if set, so that they are
+// compared on identical task sets.
+type ExperimentConfig struct {
+	Utilizations []float64 `yaml:"utilizations" validate:"dive,min=0,max=1"`
+	TaskSets     int       `yaml:"task_sets" validate:"min=0"`
+	Algorithms   []string  `yaml:"algorithms" validate:"dive,oneof=edf edf-vd dm rm amc"`
+	Protocols    []string  `yaml:"protocols" validate:"dive,oneof=none npcs pip pcp srp"`
 }
 
 func defineValidators(validate *validator.Validate) {
@@ -89,11 +170,23 @@ func LoadConfig(filePath string) (*Config, error) {
 		return nil, err
 	}
 
+	// Configurations written before multi-unit resources leave the unit ranges
+	// out: resources then have a single unit taken whole by every critical section.
+	if cfg.ResourceUnits == [2]int{} {
+		cfg.ResourceUnits = [2]int{1, 1}
+	}
+	if cfg.CSUnits == [2]int{} {
+		cfg.CSUnits = [2]int{1, 1}
+	}
+
 	validate := validator.New()
 	defineValidators(validate)
 	if err := validate.Struct(cfg); err != nil {
 		return nil, err
 	}
 
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
 	return &cfg, nil
 }
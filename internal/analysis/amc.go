@@ -0,0 +1,236 @@
+package analysis
+
+import (
+	"math"
+
+	"github.com/99109766/fms-scheduler/internal/resources"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// fpTask is a task with the blocking times it suffers under fixed priorities:
+// by all the tasks of lower priority in Normal mode and across the mode switch,
+// and by the HC ones only in Overrun mode.
+type fpTask struct {
+	*tasks.Task
+	blocking, blockingHI float64
+}
+
+// fpTasks returns copies of the tasks, highest priority first, with priorities
+// assigned by tasks.DeterminePriorityLevels and preemption levels following them.
+// Blocking times are those of SRP and PCP with resource ceilings derived from
+// these priorities. The task set and the resources are left untouched.
+func fpTasks(taskSet []*tasks.Task, resourceList []*resources.Resource) []fpTask {
+	ranked := make([]*tasks.Task, len(taskSet))
+	for i, t := range taskSet {
+		copied := *t
+		ranked[i] = &copied
+	}
+	tasks.DeterminePriorityLevels(ranked)
+	tasks.AssignPreemptionLevels(ranked)
+
+	ceilings := make([]*resources.Resource, len(resourceList))
+	for i, r := range resourceList {
+		copied := *r
+		ceilings[i] = &copied
+	}
+	tasks.ComputeResourceCeilings(ranked, ceilings)
+
+	var hc []*tasks.Task
+	for _, t := range ranked {
+		if t.Criticality == tasks.HC {
+			hc = append(hc, t)
+		}
+	}
+
+	items := make([]fpTask, len(ranked))
+	for i, t := range ranked {
+		items[i] = fpTask{
+			Task:       t,
+			blocking:   tasks.BlockingTime(t, ranked, ceilings),
+			blockingHI: tasks.BlockingTime(t, hc, ceilings),
+		}
+	}
+	return items
+}
+
+// hiBudget returns the budget of the task in Overrun mode: WCET1+WCET2 for HC
+// tasks and WCET1 otherwise.
+func hiBudget(t *tasks.Task) float64 {
+	if t.Criticality == tasks.HC {
+		return t.WCET1 + t.WCET2
+	}
+	return t.WCET1
+}
+
+// releases returns the number of releases of a task with the given period in a
+// window of the given length starting at one of its releases.
+func releases(window, period float64) float64 {
+	return math.Ceil(window/period - tolerance)
+}
+
+// fixedPoint iterates r = next(r) from the start value until it converges, or
+// until it exceeds the limit, and returns the last value.
+func fixedPoint(start, limit float64, next func(r float64) float64) float64 {
+	r := start
+	for {
+		following := next(r)
+		if following <= r+tolerance || following > limit+tolerance {
+			return following
+		}
+		r = following
+	}
+}
+
+// amcAnalysis runs the AMC-rtb or AMC-max response-time analysis of Baruah,
+// Burns and Davis on the tasks ranked by tasks.DeterminePriorityLevels. For
+// every task i, with B_i its blocking time, hp(i) the tasks of higher priority
+// and hpH(i) and hpL(i) the HC and LC ones among them:
+//
+//	R_i(LO) = C_i(LO) + B_i + sum_{j in hp(i)} ceil(R_i(LO)/T_j) C_j(LO)
+//	R_i(HI) = C_i(HI) + B_i + sum_{j in hpH(i)} ceil(R_i(HI)/T_j) C_j(HI)
+//
+// where the blocking in Overrun mode only comes from HC tasks. Across the mode
+// switch, AMC-rtb bounds the interference of LC tasks by R_i(LO):
+//
+//	R_i* = C_i(HI) + B_i + sum_{j in hpH(i)} ceil(R_i*/T_j) C_j(HI)
+//	                     + sum_{k in hpL(i)} ceil(R_i(LO)/T_k) C_k(LO)
+//
+// while AMC-max takes the worst switch instant s < R_i(LO) among the releases
+// of LC tasks, counting the jobs of HC tasks released before the switch with
+// their LO budget:
+//
+//	R_i(s) = C_i(HI) + B_i + sum_{k in hpL(i)} (floor(s/T_k)+1) C_k(LO)
+//	       + sum_{j in hpH(i)} M(j,s,R_i(s)) C_j(HI) + (ceil(R_i(s)/T_j) - M(j,s,R_i(s))) C_j(LO)
+//
+// with M(j,s,t) = min(ceil((t-s-(T_j-D_j))/T_j)+1, ceil(t/T_j)). LC tasks are
+// only analysed in Normal mode. Response times are computed up to the deadline.
+func amcAnalysis(test string, taskSet []*tasks.Task, resourceList []*resources.Resource) []ResponseTime {
+	items := fpTasks(taskSet, resourceList)
+	times := make([]ResponseTime, 0, len(items))
+	for i, t := range items {
+		hp := items[:i]
+		rt := ResponseTime{
+			Test:        test,
+			TaskID:      t.ID,
+			Criticality: t.Criticality,
+			Priority:    t.Priority,
+			Deadline:    t.Deadline,
+			Blocking:    t.blocking,
+		}
+
+		rt.LO = fixedPoint(t.WCET1+t.blocking, t.Deadline, func(r float64) float64 {
+			total := t.WCET1 + t.blocking
+			for _, j := range hp {
+				total += releases(r, j.Period) * j.WCET1
+			}
+			return total
+		})
+
+		if t.Criticality == tasks.HC {
+			budget := hiBudget(t.Task)
+			rt.HI = fixedPoint(budget+t.blockingHI, t.Deadline, func(r float64) float64 {
+				total := budget + t.blockingHI
+				for _, j := range hp {
+					if j.Criticality == tasks.HC {
+						total += releases(r, j.Period) * hiBudget(j.Task)
+					}
+				}
+				return total
+			})
+
+			if test == AMCMaxTest {
+				rt.Transition = amcMaxTransition(t, hp, rt.LO)
+			} else {
+				rt.Transition = amcRTBTransition(t, hp, rt.LO)
+			}
+		}
+
+		rt.Schedulable = rt.LO <= t.Deadline+tolerance && rt.HI <= t.Deadline+tolerance && rt.Transition <= t.Deadline+tolerance
+		times = append(times, rt)
+	}
+	return times
+}
+
+// amcRTBTransition returns the AMC-rtb response time of the HC task across the
+// mode switch given its response time in Normal mode.
+func amcRTBTransition(t fpTask, hp []fpTask, lo float64) float64 {
+	base := hiBudget(t.Task) + t.blocking
+	for _, k := range hp {
+		if k.Criticality == tasks.LC {
+			base += releases(lo, k.Period) * k.WCET1
+		}
+	}
+	return fixedPoint(base, t.Deadline, func(r float64) float64 {
+		total := base
+		for _, j := range hp {
+			if j.Criticality == tasks.HC {
+				total += releases(r, j.Period) * hiBudget(j.Task)
+			}
+		}
+		return total
+	})
+}
+
+// amcMaxTransition returns the AMC-max response time of the HC task across the
+// mode switch: the largest response time over the switch instants before its
+// response time in Normal mode at which LC tasks are released.
+func amcMaxTransition(t fpTask, hp []fpTask, lo float64) float64 {
+	switches := []float64{0}
+	for _, k := range hp {
+		if k.Criticality == tasks.LC {
+			for s := k.Period; s < lo; s += k.Period {
+				switches = append(switches, s)
+			}
+		}
+	}
+
+	worst := 0.0
+	for _, s := range switches {
+		base := hiBudget(t.Task) + t.blocking
+		for _, k := range hp {
+			if k.Criticality == tasks.LC {
+				base += (math.Floor(s/k.Period+tolerance) + 1) * k.WCET1
+			}
+		}
+
+		r := fixedPoint(base, t.Deadline, func(r float64) float64 {
+			total := base
+			for _, j := range hp {
+				if j.Criticality != tasks.HC {
+					continue
+				}
+				jobs := releases(r, j.Period)
+				hiJobs := math.Min(math.Max(releases(r-s-(j.Period-j.Deadline), j.Period)+1, 0), jobs)
+				total += hiJobs*hiBudget(j.Task) + (jobs-hiJobs)*j.WCET1
+			}
+			return total
+		})
+		worst = math.Max(worst, r)
+		if worst > t.Deadline+tolerance {
+			break
+		}
+	}
+	return worst
+}
+
+// amcLoads returns the loads of the response-time analysis: the largest ratio of
+// the response time to the deadline in Normal mode, and in Overrun mode and
+// across the switch.
+func amcLoads(times []ResponseTime) (float64, float64) {
+	normal, overrun := 0.0, 0.0
+	for _, rt := range times {
+		normal = math.Max(normal, rt.LO/rt.Deadline)
+		overrun = math.Max(overrun, math.Max(rt.HI, rt.Transition)/rt.Deadline)
+	}
+	return normal, overrun
+}
+
+// addResponseTimes runs the response-time analysis and adds its results.
+func (r *Report) addResponseTimes(test string, taskSet []*tasks.Task, resourceList []*resources.Resource) {
+	times := amcAnalysis(test, taskSet, resourceList)
+	normal, overrun := amcLoads(times)
+	r.add(test, scheduler.Normal, normal)
+	r.add(test, scheduler.Overrun, overrun)
+	r.ResponseTimes = append(r.ResponseTimes, times...)
+}
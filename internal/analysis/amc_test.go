@@ -0,0 +1,125 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/99109766/fms-scheduler/internal/resources"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// amcTaskSet is a worked example for the AMC analyses, ranked by period:
+//
+//	task 1: HC, T = D = 10,  C(LO) = 2,  C(HI) = 4
+//	task 2: LC, T = D = 15,  C(LO) = 3
+//	task 3: HC, T = D = 100, C(LO) = 20, C(HI) = 30
+//
+// For task 3, R(LO) = 37 and R(HI) = 50. AMC-rtb charges task 2 for
+// ceil(37/15) = 3 jobs across the switch: R* = 39 + ceil(R*/10)*4 = 67. AMC-max
+// tries the switch at s = 0, 15 and 30, with LC interference 3, 6 and 9, and
+// task 1 charged C(HI) for its jobs released after the switch only: R(0) = 57,
+// R(15) = 60 and R(30) = 59, so R* = 60.
+func amcTaskSet() []*tasks.Task {
+	return []*tasks.Task{
+		{ID: 3, Criticality: tasks.HC, Period: 100, Deadline: 100, WCET1: 20, WCET2: 10},
+		{ID: 1, Criticality: tasks.HC, Period: 10, Deadline: 10, WCET1: 2, WCET2: 2},
+		{ID: 2, Criticality: tasks.LC, Period: 15, Deadline: 15, WCET1: 3},
+	}
+}
+
+func TestAMCAnalysis(t *testing.T) {
+	// With task 3 using resource 1 in [0, 1] as task 1 does, tasks 1 and 2 are
+	// blocked for 1 time unit in every mode.
+	shared := amcTaskSet()
+	shared[0].CriticalSections = []*tasks.CriticalSection{{ResourceID: 1, Units: 1, Start: 0, Duration: 1}}
+	shared[1].CriticalSections = []*tasks.CriticalSection{{ResourceID: 1, Units: 1, Start: 0, Duration: 1}}
+
+	type times struct {
+		taskID        int
+		lo, hi        float64
+		rtb, max      float64
+		blocking      float64
+		unschedulable bool
+	}
+	tests := []struct {
+		name      string
+		taskSet   []*tasks.Task
+		resources []*resources.Resource
+		want      []times
+	}{
+		{
+			name:    "independent tasks",
+			taskSet: amcTaskSet(),
+			want: []times{
+				{taskID: 1, lo: 2, hi: 4, rtb: 4, max: 4},
+				{taskID: 2, lo: 5},
+				{taskID: 3, lo: 37, hi: 50, rtb: 67, max: 60},
+			},
+		},
+		{
+			name:      "shared resource",
+			taskSet:   shared,
+			resources: []*resources.Resource{{ID: 1, Units: 1}},
+			want: []times{
+				{taskID: 1, lo: 3, hi: 5, rtb: 5, max: 5, blocking: 1},
+				{taskID: 2, lo: 6, blocking: 1},
+				{taskID: 3, lo: 37, hi: 50, rtb: 67, max: 60},
+			},
+		},
+		{
+			name: "deadline exceeded",
+			taskSet: []*tasks.Task{
+				{ID: 1, Criticality: tasks.HC, Period: 10, Deadline: 10, WCET1: 4, WCET2: 4},
+				{ID: 2, Criticality: tasks.HC, Period: 20, Deadline: 20, WCET1: 4, WCET2: 8},
+			},
+			want: []times{
+				{taskID: 1, lo: 4, hi: 8, rtb: 8, max: 8},
+				{taskID: 2, lo: 8, hi: 28, rtb: 28, max: 28, unschedulable: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, test := range []string{AMCRTBTest, AMCMaxTest} {
+				got := amcAnalysis(test, tt.taskSet, tt.resources)
+				if len(got) != len(tt.want) {
+					t.Fatalf("%s: %d response times, want %d", test, len(got), len(tt.want))
+				}
+				for i, want := range tt.want {
+					transition := want.rtb
+					if test == AMCMaxTest {
+						transition = want.max
+					}
+					rt := got[i]
+					if rt.TaskID != want.taskID || rt.Priority != i+1 {
+						t.Errorf("%s: response time %d is task %d at priority %d, want task %d at priority %d",
+							test, i, rt.TaskID, rt.Priority, want.taskID, i+1)
+					}
+					if !near(rt.LO, want.lo) || !near(rt.HI, want.hi) || !near(rt.Transition, transition) || !near(rt.Blocking, want.blocking) {
+						t.Errorf("%s: task %d: LO %g, HI %g, transition %g, blocking %g, want %g, %g, %g, %g",
+							test, rt.TaskID, rt.LO, rt.HI, rt.Transition, rt.Blocking, want.lo, want.hi, transition, want.blocking)
+					}
+					if rt.Schedulable == want.unschedulable {
+						t.Errorf("%s: task %d schedulable = %v, want %v", test, rt.TaskID, rt.Schedulable, !want.unschedulable)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAMCLeavesTaskSetUntouched(t *testing.T) {
+	taskSet := amcTaskSet()
+	r := &resources.Resource{ID: 1, Units: 1, Ceiling: 7, Ceilings: []int{7, 9}}
+	amcAnalysis(AMCMaxTest, taskSet, []*resources.Resource{r})
+
+	for i, id := range []int{3, 1, 2} {
+		if taskSet[i].ID != id || taskSet[i].Priority != 0 || taskSet[i].PreemptionLevel != 0 {
+			t.Errorf("task set changed: position %d holds task %d with priority %d and level %d",
+				i, taskSet[i].ID, taskSet[i].Priority, taskSet[i].PreemptionLevel)
+		}
+	}
+	if r.Ceiling != 7 || r.Ceilings[0] != 7 || r.Ceilings[1] != 9 {
+		t.Errorf("resource ceilings changed to %d, %v", r.Ceiling, r.Ceilings)
+	}
+}
@@ -0,0 +1,226 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+
+	"github.com/99109766/fms-scheduler/internal/resources"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// tolerance absorbs rounding errors when comparing loads against 1.
+const tolerance = 1e-9
+
+// maxCheckpoints bounds the number of deadlines the processor-demand test checks.
+// If the bound is reached, the test fails conservatively.
+const maxCheckpoints = 1000000
+
+// modeTask is a task together with its execution budget in a given mode.
+type modeTask struct {
+	*tasks.Task
+	wcet float64
+}
+
+// Analyze runs all schedulability tests on the task set. The SRP based tests are
+// run for Normal mode, where every task runs with its WCET1, and for Overrun mode,
+// where only HC tasks run, with WCET1+WCET2. Preemption levels and resource
+// ceilings must have been computed beforehand. The fixed-priority AMC-rtb and
+// AMC-max analyses rank the tasks by tasks.DeterminePriorityLevels instead, on
+// copies of the tasks. The lock-order cycles of the task set are reported as well.
+func Analyze(taskSet []*tasks.Task, resourceList []*resources.Resource) *Report {
+	report := &Report{LockCycles: LockCycles(taskSet, resourceList)}
+	for _, mode := range []scheduler.Mode{scheduler.Normal, scheduler.Overrun} {
+		items := modeTasks(taskSet, mode)
+		report.add(UtilizationTest, mode, utilizationLoad(items, resourceList))
+		report.add(DemandTest, mode, demandLoad(items, resourceList))
+	}
+
+	normal, overrun := edfVDLoads(taskSet)
+	report.add(EDFVDTest, scheduler.Normal, normal)
+	report.add(EDFVDTest, scheduler.Overrun, overrun)
+
+	report.addResponseTimes(AMCRTBTest, taskSet, resourceList)
+	report.addResponseTimes(AMCMaxTest, taskSet, resourceList)
+
+	return report
+}
+
+// add appends the result of a test given its load.
+func (r *Report) add(test string, mode scheduler.Mode, load float64) {
+	r.Results = append(r.Results, Result{
+		Test:        test,
+		Mode:        mode,
+		Schedulable: load <= 1+tolerance,
+		Load:        load,
+	})
+}
+
+// modeTasks returns the tasks running in the given mode with their budgets.
+func modeTasks(taskSet []*tasks.Task, mode scheduler.Mode) []modeTask {
+	items := make([]modeTask, 0, len(taskSet))
+	for _, t := range taskSet {
+		switch {
+		case mode == scheduler.Normal:
+			items = append(items, modeTask{Task: t, wcet: t.WCET1})
+		case t.Criticality == tasks.HC:
+			items = append(items, modeTask{Task: t, wcet: t.WCET1 + t.WCET2})
+		}
+	}
+	return items
+}
+
+// taskList returns the tasks of the mode tasks.
+func taskList(items []modeTask) []*tasks.Task {
+	list := make([]*tasks.Task, len(items))
+	for i, t := range items {
+		list[i] = t.Task
+	}
+	return list
+}
+
+// utilizationLoad implements Baker's density test for EDF with SRP: with tasks
+// sorted by relative deadline, for every task k
+//
+//	sum_{i<=k} C_i / D_i + B_k / D_k <= 1
+//
+// where B_k is the SRP blocking time of task k by the tasks running in the mode.
+func utilizationLoad(items []modeTask, resourceList []*resources.Resource) float64 {
+	sorted := make([]modeTask, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Deadline < sorted[j].Deadline
+	})
+
+	others := taskList(items)
+
+	load, density := 0.0, 0.0
+	for _, t := range sorted {
+		density += t.wcet / math.Min(t.Deadline, t.Period)
+		load = math.Max(load, density+tasks.BlockingTime(t.Task, others, resourceList)/t.Deadline)
+	}
+	return load
+}
+
+// demandLoad implements the processor-demand test for EDF with SRP blocking:
+// for every absolute deadline L up to the busy period bound,
+//
+//	dbf(L) + b(L) <= L
+//
+// where b(L) is the longest critical section of a task with relative deadline
+// greater than L that can block a task with relative deadline at most L.
+func demandLoad(items []modeTask, resourceList []*resources.Resource) float64 {
+	if len(items) == 0 {
+		return 0
+	}
+
+	utilization, slackSum, maxDeadline := 0.0, 0.0, 0.0
+	for _, t := range items {
+		u := t.wcet / t.Period
+		utilization += u
+		slackSum += math.Max(t.Period-t.Deadline, 0) * u
+		maxDeadline = math.Max(maxDeadline, t.Deadline)
+	}
+	if utilization > 1+tolerance {
+		return utilization
+	}
+
+	blockers := demandBlockers(items, resourceList)
+	maxBlocking := 0.0
+	for _, b := range blockers {
+		maxBlocking = math.Max(maxBlocking, b.duration)
+	}
+
+	bound := maxDeadline
+	if utilization < 1-tolerance {
+		bound = math.Max(bound, (slackSum+maxBlocking)/(1-utilization))
+	} else {
+		bound += tasks.Hyperperiod(taskList(items))
+	}
+
+	checkpoints := make([]float64, 0)
+	for _, t := range items {
+		for d := t.Deadline; d <= bound; d += t.Period {
+			if len(checkpoints) >= maxCheckpoints {
+				return math.Max(utilization, 1) + 2*tolerance
+			}
+			checkpoints = append(checkpoints, d)
+		}
+	}
+	sort.Float64s(checkpoints)
+
+	load := 0.0
+	for i, l := range checkpoints {
+		if i > 0 && l == checkpoints[i-1] {
+			continue
+		}
+
+		demand := 0.0
+		for _, t := range items {
+			if l >= t.Deadline {
+				demand += (math.Floor((l-t.Deadline)/t.Period) + 1) * t.wcet
+			}
+		}
+		load = math.Max(load, (demand+maxBlockingAt(blockers, l))/l)
+	}
+	return load
+}
+
+// demandBlocker is a critical section that can block tasks whose relative
+// deadlines lie in [from, until).
+type demandBlocker struct {
+	from, until float64
+	duration    float64
+}
+
+// demandBlockers returns, for every critical section, the range of relative
+// deadlines it can block: from the shortest deadline of another task that needs
+// more units of the resource than left available while the section runs, up to
+// the deadline of the task owning the section.
+func demandBlockers(items []modeTask, resourceList []*resources.Resource) []demandBlocker {
+	units := make(map[int]int)
+	for _, r := range resourceList {
+		units[r.ID] = r.Units
+	}
+
+	blockers := make([]demandBlocker, 0)
+	for _, owner := range items {
+		for i, cs := range owner.CriticalSections {
+			from := math.Inf(1)
+			for _, other := range items {
+				if other.Task == owner.Task || other.Deadline >= owner.Deadline {
+					continue
+				}
+				if other.ResourceDemand(cs.ResourceID) > units[cs.ResourceID]-owner.HeldUnits(i) && other.Deadline < from {
+					from = other.Deadline
+				}
+			}
+			if !math.IsInf(from, 1) {
+				blockers = append(blockers, demandBlocker{from: from, until: owner.Deadline, duration: cs.Duration})
+			}
+		}
+	}
+	return blockers
+}
+
+// maxBlockingAt returns b(L), the longest critical section blocking at interval length l.
+func maxBlockingAt(blockers []demandBlocker, l float64) float64 {
+	blocking := 0.0
+	for _, b := range blockers {
+		if b.from <= l && l < b.until {
+			blocking = math.Max(blocking, b.duration)
+		}
+	}
+	return blocking
+}
+
+// edfVDLoads returns the loads of the EDF-VD sufficient test. In Normal mode,
+// the factor x = U_HC(LO) / (1 - U_LC(LO)) lies in (0, 1] if and only if
+// U_LC(LO) + U_HC(LO) <= 1, which is the condition reported. Deadlines scaled by
+// x then keep the Normal mode schedulable by construction. In Overrun mode,
+// x*U_LC(LO) + U_HC(HI) <= 1 must hold, with x = 1 (plain EDF) if no factor exists.
+func edfVDLoads(taskSet []*tasks.Task) (float64, float64) {
+	lcLO, hcLO, hcHI := tasks.CriticalityUtilizations(taskSet)
+	x, _ := tasks.VirtualDeadlineFactor(taskSet)
+	return lcLO + hcLO, x*lcLO + hcHI
+}
@@ -0,0 +1,114 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/99109766/fms-scheduler/internal/resources"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+func TestDemandLoad(t *testing.T) {
+	task := func(id int, period, deadline, wcet float64, sections ...*tasks.CriticalSection) modeTask {
+		return modeTask{
+			Task: &tasks.Task{ID: id, Period: period, Deadline: deadline, WCET1: wcet, CriticalSections: sections},
+			wcet: wcet,
+		}
+	}
+	section := func(start, duration float64) *tasks.CriticalSection {
+		return &tasks.CriticalSection{ResourceID: 1, Units: 1, Start: start, Duration: duration}
+	}
+
+	tests := []struct {
+		name      string
+		items     []modeTask
+		resources []*resources.Resource
+		load      float64
+	}{
+		{
+			name: "no tasks",
+		},
+		{
+			// dbf(4) = 1 and dbf(5) = 3.
+			name:  "constrained deadline",
+			items: []modeTask{task(1, 4, 4, 1), task(2, 6, 5, 2)},
+			load:  0.6,
+		},
+		{
+			// U = 0.4, but dbf(3) = 4.
+			name:  "demand above the interval",
+			items: []modeTask{task(1, 10, 2, 2), task(2, 10, 3, 2)},
+			load:  4.0 / 3,
+		},
+		{
+			name:  "utilization above 1",
+			items: []modeTask{task(1, 2, 2, 3)},
+			load:  1.5,
+		},
+		{
+			// b(4) = 1.5 from the critical section of task 2: (1 + 1.5) / 4.
+			name:      "blocking",
+			items:     []modeTask{task(1, 4, 4, 1, section(0, 0.5)), task(2, 8, 8, 2, section(0, 1.5))},
+			resources: []*resources.Resource{{ID: 1, Units: 1}},
+			load:      0.625,
+		},
+		{
+			// Two units let both tasks hold the resource at once.
+			name:      "enough units",
+			items:     []modeTask{task(1, 4, 4, 1, section(0, 0.5)), task(2, 8, 8, 2, section(0, 1.5))},
+			resources: []*resources.Resource{{ID: 1, Units: 2}},
+			load:      0.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := demandLoad(tt.items, tt.resources); !near(got, tt.load) {
+				t.Errorf("demandLoad() = %g, want %g", got, tt.load)
+			}
+		})
+	}
+}
+
+func TestEDFVDLoads(t *testing.T) {
+	tests := []struct {
+		name            string
+		taskSet         []*tasks.Task
+		normal, overrun float64
+	}{
+		{
+			// x = 0.2 / (1 - 0.5) = 0.4.
+			name: "virtual deadlines",
+			taskSet: []*tasks.Task{
+				{Criticality: tasks.LC, Period: 10, WCET1: 5},
+				{Criticality: tasks.HC, Period: 10, WCET1: 2, WCET2: 4},
+			},
+			normal:  0.7,
+			overrun: 0.4*0.5 + 0.6,
+		},
+		{
+			// U_LC(LO) + U_HC(LO) > 1: x = 1 reported in Overrun mode.
+			name: "overloaded Normal mode",
+			taskSet: []*tasks.Task{
+				{Criticality: tasks.LC, Period: 10, WCET1: 6},
+				{Criticality: tasks.HC, Period: 10, WCET1: 5, WCET2: 1},
+			},
+			normal:  1.1,
+			overrun: 1.2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normal, overrun := edfVDLoads(tt.taskSet)
+			if !near(normal, tt.normal) || !near(overrun, tt.overrun) {
+				t.Errorf("edfVDLoads() = %g, %g, want %g, %g", normal, overrun, tt.normal, tt.overrun)
+			}
+		})
+	}
+}
+
+// near reports whether the values are equal up to rounding errors.
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-6
+}
@@ -0,0 +1,148 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/99109766/fms-scheduler/internal/resources"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// maxLockCycles bounds the number of lock-order cycles LockCycles reports.
+const maxLockCycles = 64
+
+// LockEdge is an edge of the lock-order graph: the task acquires resource To
+// while it holds resource From.
+type LockEdge struct {
+	From   int `json:"from"`
+	To     int `json:"to"`
+	TaskID int `json:"task_id"`
+}
+
+// LockCycle is a cycle of the lock-order graph: task Tasks[i] holds resource
+// Resources[i] and acquires the next resource of the cycle. If every task of
+// the cycle reaches its nested critical section at once, they wait for each
+// other forever unless the resource access protocol prevents it.
+type LockCycle struct {
+	Resources []int `json:"resources"`
+	Tasks     []int `json:"tasks"`
+}
+
+func (c LockCycle) String() string {
+	chain := make([]string, len(c.Tasks))
+	for i, taskID := range c.Tasks {
+		chain[i] = fmt.Sprintf("Task %d holds Resource %d and acquires Resource %d",
+			taskID, c.Resources[i], c.Resources[(i+1)%len(c.Resources)])
+	}
+	return strings.Join(chain, ", ")
+}
+
+// LockOrder returns the lock-order graph of the task set: an edge for every
+// resource a task acquires while it holds another one, in nesting order. The
+// critical sections must be properly nested and sorted in acquisition order.
+func LockOrder(taskSet []*tasks.Task) []LockEdge {
+	var edges []LockEdge
+	seen := make(map[LockEdge]bool)
+	for _, t := range taskSet {
+		for i, cs := range t.CriticalSections {
+			for _, outer := range t.Enclosing(i) {
+				edge := LockEdge{From: outer.ResourceID, To: cs.ResourceID, TaskID: t.ID}
+				if edge.From != edge.To && !seen[edge] {
+					seen[edge] = true
+					edges = append(edges, edge)
+				}
+			}
+		}
+	}
+	return edges
+}
+
+// LockCycles returns the cycles of the lock-order graph that can deadlock: the
+// tasks along a cycle are distinct, and no resource of the cycle has enough
+// units for all its users at once. Each cycle of resources is reported once,
+// starting from its lowest resource ID, and at most maxLockCycles are returned.
+func LockCycles(taskSet []*tasks.Task, resourceList []*resources.Resource) []LockCycle {
+	// Resources with enough units for all their users never block anybody.
+	scarce := make(map[int]bool)
+	for _, r := range resourceList {
+		demand := 0
+		for _, t := range taskSet {
+			demand += t.ResourceDemand(r.ID)
+		}
+		scarce[r.ID] = demand > r.Units
+	}
+
+	graph := make(map[int][]LockEdge)
+	var nodes []int
+	for _, edge := range LockOrder(taskSet) {
+		if !scarce[edge.From] || !scarce[edge.To] {
+			continue
+		}
+		if len(graph[edge.From]) == 0 {
+			nodes = append(nodes, edge.From)
+		}
+		graph[edge.From] = append(graph[edge.From], edge)
+	}
+	sort.Ints(nodes)
+
+	var cycles []LockCycle
+	found := make(map[string]bool)
+	var path []LockEdge
+	onPath := make(map[int]bool)
+	usedTasks := make(map[int]bool)
+
+	// visit extends the path from the resource, only through resources above
+	// the start so that every cycle is found from its lowest resource.
+	var visit func(start, resourceID int)
+	visit = func(start, resourceID int) {
+		for _, edge := range graph[resourceID] {
+			if len(cycles) >= maxLockCycles {
+				return
+			}
+			if usedTasks[edge.TaskID] || (edge.To != start && (edge.To < start || onPath[edge.To])) {
+				continue
+			}
+
+			path = append(path, edge)
+			usedTasks[edge.TaskID] = true
+			if edge.To == start {
+				cycle := LockCycle{}
+				for _, e := range path {
+					cycle.Resources = append(cycle.Resources, e.From)
+					cycle.Tasks = append(cycle.Tasks, e.TaskID)
+				}
+				if key := fmt.Sprint(cycle.Resources); !found[key] {
+					found[key] = true
+					cycles = append(cycles, cycle)
+				}
+			} else {
+				onPath[edge.To] = true
+				visit(start, edge.To)
+				delete(onPath, edge.To)
+			}
+			delete(usedTasks, edge.TaskID)
+			path = path[:len(path)-1]
+		}
+	}
+
+	for _, start := range nodes {
+		onPath[start] = true
+		visit(start, start)
+		delete(onPath, start)
+	}
+	return cycles
+}
+
+// DeadlockProtocols returns the resource access protocols under which the
+// lock-order cycles can deadlock.
+func DeadlockProtocols() []string {
+	var names []string
+	for _, name := range scheduler.Protocols {
+		if protocol, err := scheduler.NewProtocol(name); err == nil && !protocol.PreventsDeadlock() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,104 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/99109766/fms-scheduler/internal/resources"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// nestedTask returns a task acquiring the resources in order, each one nested in
+// the previous one.
+func nestedTask(id int, resourceIDs ...int) *tasks.Task {
+	t := &tasks.Task{ID: id, Period: 10, Deadline: 10, WCET1: 5}
+	for i, resourceID := range resourceIDs {
+		t.CriticalSections = append(t.CriticalSections, &tasks.CriticalSection{
+			ResourceID: resourceID,
+			Units:      1,
+			Start:      float64(i),
+			Duration:   float64(2*len(resourceIDs) - 2*i),
+		})
+	}
+	return t
+}
+
+// units returns single resources with the given numbers of units, numbered from 1.
+func units(counts ...int) []*resources.Resource {
+	list := make([]*resources.Resource, len(counts))
+	for i, count := range counts {
+		list[i] = &resources.Resource{ID: i + 1, Units: count}
+	}
+	return list
+}
+
+func TestLockCycles(t *testing.T) {
+	tests := []struct {
+		name      string
+		taskSet   []*tasks.Task
+		resources []*resources.Resource
+		cycles    []LockCycle
+	}{
+		{
+			name:      "opposite orders",
+			taskSet:   []*tasks.Task{nestedTask(1, 1, 2), nestedTask(2, 2, 1)},
+			resources: units(1, 1),
+			cycles:    []LockCycle{{Resources: []int{1, 2}, Tasks: []int{1, 2}}},
+		},
+		{
+			name:      "same order",
+			taskSet:   []*tasks.Task{nestedTask(1, 1, 2), nestedTask(2, 1, 2)},
+			resources: units(1, 1),
+		},
+		{
+			name:      "enough units for every user",
+			taskSet:   []*tasks.Task{nestedTask(1, 1, 2), nestedTask(2, 2, 1)},
+			resources: units(2, 1),
+		},
+		{
+			name:      "one task in both orders",
+			taskSet:   []*tasks.Task{nestedTask(1, 1, 2), nestedTask(1, 2, 1)},
+			resources: units(1, 1),
+		},
+		{
+			name:      "three tasks",
+			taskSet:   []*tasks.Task{nestedTask(1, 2, 3), nestedTask(2, 3, 1), nestedTask(3, 1, 2)},
+			resources: units(1, 1, 1),
+			cycles:    []LockCycle{{Resources: []int{1, 2, 3}, Tasks: []int{3, 1, 2}}},
+		},
+		{
+			name:      "deeper nesting",
+			taskSet:   []*tasks.Task{nestedTask(1, 1, 2, 3), nestedTask(2, 3, 1)},
+			resources: units(1, 1, 1),
+			cycles:    []LockCycle{{Resources: []int{1, 3}, Tasks: []int{1, 2}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LockCycles(tt.taskSet, tt.resources); !reflect.DeepEqual(got, tt.cycles) {
+				t.Errorf("LockCycles() = %v, want %v", got, tt.cycles)
+			}
+		})
+	}
+}
+
+func TestLockOrder(t *testing.T) {
+	got := LockOrder([]*tasks.Task{nestedTask(1, 1, 2, 3), nestedTask(2, 4)})
+	want := []LockEdge{
+		{From: 1, To: 2, TaskID: 1},
+		{From: 1, To: 3, TaskID: 1},
+		{From: 2, To: 3, TaskID: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LockOrder() = %v, want %v", got, want)
+	}
+}
+
+func TestDeadlockProtocols(t *testing.T) {
+	want := []string{scheduler.ProtocolNone, scheduler.ProtocolPIP}
+	if got := DeadlockProtocols(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DeadlockProtocols() = %v, want %v", got, want)
+	}
+}
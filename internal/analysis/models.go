@@ -0,0 +1,94 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// Names of the schedulability tests.
+const (
+	UtilizationTest = "utilization-srp"
+	DemandTest      = "demand-srp"
+	EDFVDTest       = "edf-vd"
+	AMCRTBTest      = "amc-rtb"
+	AMCMaxTest      = "amc-max"
+)
+
+// Result is the outcome of one schedulability test in one system mode.
+type Result struct {
+	Test        string         `json:"test"`
+	Mode        scheduler.Mode `json:"mode"`
+	Schedulable bool           `json:"schedulable"`
+	// Load is the largest left-hand side of the test inequality, normalised so
+	// that the test passes if and only if Load <= 1.
+	Load float64 `json:"load"`
+}
+
+func (r Result) String() string {
+	verdict := "FAIL"
+	if r.Schedulable {
+		verdict = "PASS"
+	}
+	return fmt.Sprintf("%-16s %-8v %s (load=%.3f)", r.Test, r.Mode, verdict, r.Load)
+}
+
+// ResponseTime holds the worst-case response times of a task found by a
+// fixed-priority response-time analysis: LO in Normal mode, and for HC tasks HI
+// in Overrun mode and Transition across the mode switch. Blocking is the blocking
+// time of the task in Normal mode.
+type ResponseTime struct {
+	Test        string                 `json:"test"`
+	TaskID      int                    `json:"task_id"`
+	Criticality tasks.CriticalityLevel `json:"criticality"`
+	Priority    int                    `json:"priority"`
+	Deadline    float64                `json:"deadline"`
+	Blocking    float64                `json:"blocking"`
+	LO          float64                `json:"lo"`
+	HI          float64                `json:"hi,omitempty"`
+	Transition  float64                `json:"transition,omitempty"`
+	Schedulable bool                   `json:"schedulable"`
+}
+
+func (rt ResponseTime) String() string {
+	verdict := "FAIL"
+	if rt.Schedulable {
+		verdict = "PASS"
+	}
+	s := fmt.Sprintf("%-8s Task %3d %v Priority=%-3d Deadline=%8.3f Blocking=%7.3f R(LO)=%8.3f",
+		rt.Test, rt.TaskID, rt.Criticality, rt.Priority, rt.Deadline, rt.Blocking, rt.LO)
+	if rt.Criticality == tasks.HC {
+		s += fmt.Sprintf(" R(HI)=%8.3f R*=%8.3f", rt.HI, rt.Transition)
+	}
+	return s + " " + verdict
+}
+
+// Report gathers the results of all schedulability tests for a task set.
+type Report struct {
+	// TaskSetSeed is the seed the task set was generated with, if it was generated.
+	TaskSetSeed int64    `json:"task_set_seed,omitempty"`
+	Results     []Result `json:"results"`
+
+	// ResponseTimes lists the response times of every task found by the
+	// fixed-priority AMC analyses.
+	ResponseTimes []ResponseTime `json:"response_times"`
+
+	// LockCycles lists the nested resource accesses that can deadlock under the
+	// protocols not preventing it.
+	LockCycles []LockCycle `json:"lock_cycles,omitempty"`
+}
+
+// Passed reports whether the test passed in every mode it was run for.
+func (r *Report) Passed(test string) bool {
+	found := false
+	for _, res := range r.Results {
+		if res.Test == test {
+			if !res.Schedulable {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
@@ -0,0 +1,271 @@
+package experiment
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/analysis"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// SimulationColumn is the column holding the ratio of task sets simulated
+// without any deadline miss. When the experiment compares several scheduling
+// algorithms or resource access protocols, each combination gets its own column
+// named after SimulationColumn, the algorithm and the protocol, as in
+// "simulation-edf" or "simulation-edf-pcp".
+const SimulationColumn = "simulation"
+
+// analysisColumns lists the schedulability test columns in output order.
+var analysisColumns = []string{analysis.UtilizationTest, analysis.DemandTest, analysis.EDFVDTest, analysis.AMCRTBTest, analysis.AMCMaxTest}
+
+// variant is a combination of scheduling algorithm and resource access protocol
+// the task sets are simulated with.
+type variant struct {
+	column    string
+	algorithm string
+	protocol  string
+}
+
+// variants returns the combinations of the algorithms and protocols compared by
+// the experiment, which default to the ones of the configuration.
+func variants(cfg *config.Config) []variant {
+	algorithms, protocols := cfg.Experiment.Algorithms, cfg.Experiment.Protocols
+	if len(algorithms) == 0 {
+		algorithms = []string{cfg.Algorithm}
+	}
+	if len(protocols) == 0 {
+		protocols = []string{cfg.Protocol}
+	}
+
+	var list []variant
+	for _, algorithm := range algorithms {
+		for _, protocol := range protocols {
+			column := SimulationColumn
+			if len(cfg.Experiment.Algorithms) > 0 {
+				column += "-" + algorithm
+			}
+			if len(cfg.Experiment.Protocols) > 0 {
+				column += "-" + protocol
+			}
+			list = append(list, variant{column: column, algorithm: algorithm, protocol: protocol})
+		}
+	}
+	return list
+}
+
+// Columns returns the acceptance ratio columns of the experiment in output order.
+func Columns(cfg *config.Config) []string {
+	columns := append([]string(nil), analysisColumns...)
+	for _, v := range variants(cfg) {
+		columns = append(columns, v.column)
+	}
+	return columns
+}
+
+// Report holds the acceptance ratios of an experiment and the seed it was run with.
+// Simulations lists the simulation columns among Columns.
+type Report struct {
+	Seed        int64    `json:"seed"`
+	Columns     []string `json:"columns"`
+	Simulations []string `json:"simulations"`
+	Rows        []Row    `json:"rows"`
+}
+
+// Row holds the acceptance ratios measured at one total utilization. Seeds lists
+// the seed of every task set: generating with the same configuration, the
+// utilization of the row and one of these seeds yields that task set again, and
+// simulating it with the same seed reproduces its simulation. For every
+// simulation column, Blocking is the average time a job spent waiting while a
+// job of lower priority ran, and MissRate the share of released jobs that
+// missed their deadline.
+type Row struct {
+	Utilization float64            `json:"utilization"`
+	TaskSets    int                `json:"task_sets"`
+	Seeds       []int64            `json:"seeds"`
+	Ratios      map[string]float64 `json:"ratios"`
+	Blocking    map[string]float64 `json:"blocking"`
+	MissRate    map[string]float64 `json:"miss_rate"`
+
+	columns []string
+}
+
+// outcome is the evaluation of one task set: the verdict of every column and,
+// for every simulation column, the number of jobs released and missed and their
+// total blocking time.
+type outcome struct {
+	verdicts map[string]bool
+	jobs     map[string]int
+	misses   map[string]int
+	blocking map[string]float64
+}
+
+// Run sweeps the utilizations of cfg.Experiment. For every utilization it
+// generates cfg.Experiment.TaskSets random task sets with the rest of cfg, runs
+// the schedulability tests and the simulation on each of them, and returns the
+// ratio of accepted task sets per test. Every task set is simulated under each
+// combination of cfg.Experiment.Algorithms and cfg.Experiment.Protocols. Task
+// sets are processed in parallel, each with its own random source seeded from
+// cfg.Seed, so the results do not depend on the order the workers run in.
+func Run(cfg *config.Config) *Report {
+	seeds := rand.New(rand.NewSource(cfg.Seed))
+	report := &Report{Seed: cfg.Seed, Columns: Columns(cfg), Rows: make([]Row, 0, len(cfg.Experiment.Utilizations))}
+	for _, v := range variants(cfg) {
+		report.Simulations = append(report.Simulations, v.column)
+	}
+
+	for _, utilization := range cfg.Experiment.Utilizations {
+		row := Row{
+			Utilization: utilization,
+			TaskSets:    cfg.Experiment.TaskSets,
+			Ratios:      make(map[string]float64),
+			Blocking:    make(map[string]float64),
+			MissRate:    make(map[string]float64),
+			columns:     report.Columns,
+		}
+		for i := 0; i < row.TaskSets; i++ {
+			row.Seeds = append(row.Seeds, seeds.Int63())
+		}
+
+		total := outcome{
+			jobs:     make(map[string]int),
+			misses:   make(map[string]int),
+			blocking: make(map[string]float64),
+		}
+		accepted := make(map[string]int)
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, runtime.NumCPU())
+		for _, seed := range row.Seeds {
+			pointCfg := *cfg
+			pointCfg.TotalUtility = utilization
+			pointCfg.Seed = seed
+			pointCfg.Quiet = true
+
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				result := evaluate(&pointCfg)
+				mu.Lock()
+				for column, ok := range result.verdicts {
+					if ok {
+						accepted[column]++
+					}
+				}
+				for column := range result.jobs {
+					total.jobs[column] += result.jobs[column]
+					total.misses[column] += result.misses[column]
+					total.blocking[column] += result.blocking[column]
+				}
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		for _, column := range report.Columns {
+			if row.TaskSets > 0 {
+				row.Ratios[column] = float64(accepted[column]) / float64(row.TaskSets)
+			}
+		}
+		for _, column := range report.Simulations {
+			if jobs := total.jobs[column]; jobs > 0 {
+				row.Blocking[column] = total.blocking[column] / float64(jobs)
+				row.MissRate[column] = float64(total.misses[column]) / float64(jobs)
+			}
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	return report
+}
+
+// evaluate generates one task set from cfg.Seed, runs the schedulability tests
+// and simulates it under every variant.
+func evaluate(cfg *config.Config) outcome {
+	taskSet, resourceList := tasks.GenerateTaskSet(cfg, rand.New(rand.NewSource(cfg.Seed)))
+
+	result := outcome{
+		verdicts: make(map[string]bool),
+		jobs:     make(map[string]int),
+		misses:   make(map[string]int),
+		blocking: make(map[string]float64),
+	}
+	report := analysis.Analyze(taskSet, resourceList)
+	for _, column := range analysisColumns {
+		result.verdicts[column] = report.Passed(column)
+	}
+
+	for _, v := range variants(cfg) {
+		// The preemption levels must follow the priorities of the algorithm.
+		variantCfg := *cfg
+		variantCfg.Algorithm, variantCfg.Protocol = v.algorithm, v.protocol
+		tasks.ComputePreemptionLevels(&variantCfg, taskSet, resourceList)
+		tasks.ComputeBlockingTimes(taskSet, resourceList)
+
+		simulation, err := scheduler.RunScheduler(&variantCfg, taskSet, resourceList)
+		result.verdicts[v.column] = err == nil && len(simulation.Misses) == 0
+		if err != nil {
+			continue
+		}
+		for _, job := range simulation.Jobs {
+			result.jobs[v.column]++
+			result.blocking[v.column] += job.BlockingTime
+			if job.Missed {
+				result.misses[v.column]++
+			}
+		}
+	}
+	return result
+}
+
+// WriteCSV writes the rows as a CSV table with one column per acceptance ratio,
+// followed by the average blocking time and the miss rate of every simulation
+// column, and the seed of the experiment in every row.
+func WriteCSV(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+	header := append([]string{"seed", "utilization", "task_sets"}, report.Columns...)
+	for _, column := range report.Simulations {
+		header = append(header, column+"-blocking", column+"-miss-rate")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range report.Rows {
+		record := []string{
+			strconv.FormatInt(report.Seed, 10),
+			strconv.FormatFloat(row.Utilization, 'f', -1, 64),
+			strconv.Itoa(row.TaskSets),
+		}
+		for _, column := range report.Columns {
+			record = append(record, strconv.FormatFloat(row.Ratios[column], 'f', 4, 64))
+		}
+		for _, column := range report.Simulations {
+			record = append(record,
+				strconv.FormatFloat(row.Blocking[column], 'f', 4, 64),
+				strconv.FormatFloat(row.MissRate[column], 'f', 4, 64))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (r Row) String() string {
+	s := fmt.Sprintf("U=%.2f (%d sets):", r.Utilization, r.TaskSets)
+	for _, column := range r.columns {
+		s += fmt.Sprintf(" %s=%.2f", column, r.Ratios[column])
+	}
+	return s
+}
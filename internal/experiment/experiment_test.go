@@ -0,0 +1,109 @@
+package experiment
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+
+	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/analysis"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// experimentConfig returns a small experiment on generated task sets.
+func experimentConfig() *config.Config {
+	return &config.Config{
+		NumResources:  3,
+		ResourceUnits: [2]int{1, 2},
+		NumTasks:      5,
+		PeriodRange:   [2]float64{50, 200},
+		DeadlineRatio: [2]float64{0.9, 1},
+		WCETRatio:     [2]float64{0.5, 0.8},
+		HighRatio:     0.4,
+		ResourceUsage: [2]int{1, 2},
+		CSFactor:      0.5,
+		CSRange:       [2]int{1, 2},
+		CSUnits:       [2]int{1, 1},
+		SimulateTime:  400,
+		Seed:          42,
+		Experiment: config.ExperimentConfig{
+			Utilizations: []float64{0.3, 0.9},
+			TaskSets:     4,
+		},
+	}
+}
+
+func TestColumns(t *testing.T) {
+	cfg := experimentConfig()
+	analyses := []string{analysis.UtilizationTest, analysis.DemandTest, analysis.EDFVDTest, analysis.AMCRTBTest, analysis.AMCMaxTest}
+	if got, want := Columns(cfg), append(analyses, SimulationColumn); !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns() = %v, want %v", got, want)
+	}
+
+	cfg.Experiment.Algorithms = []string{tasks.EDF, tasks.DM}
+	cfg.Experiment.Protocols = []string{"pcp"}
+	want := append(append([]string(nil), analyses...), "simulation-edf-pcp", "simulation-dm-pcp")
+	if got := Columns(cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns() = %v, want %v", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	cfg := experimentConfig()
+	cfg.Experiment.Algorithms = []string{tasks.EDF, tasks.AMC}
+	report := Run(cfg)
+
+	if !reflect.DeepEqual(report.Simulations, []string{"simulation-edf", "simulation-amc"}) {
+		t.Errorf("simulation columns = %v", report.Simulations)
+	}
+	if len(report.Rows) != len(cfg.Experiment.Utilizations) {
+		t.Fatalf("%d rows, want %d", len(report.Rows), len(cfg.Experiment.Utilizations))
+	}
+	for _, row := range report.Rows {
+		if len(row.Seeds) != cfg.Experiment.TaskSets {
+			t.Errorf("U=%g: %d seeds, want %d", row.Utilization, len(row.Seeds), cfg.Experiment.TaskSets)
+		}
+		for _, column := range report.Columns {
+			if ratio := row.Ratios[column]; ratio < 0 || ratio > 1 {
+				t.Errorf("U=%g: %s ratio %g", row.Utilization, column, ratio)
+			}
+		}
+	}
+
+	// The workers share no random source: the same seed gives the same report.
+	if again := Run(cfg); !reflect.DeepEqual(again, report) {
+		t.Errorf("a second run with seed %d gives a different report", cfg.Seed)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	report := &Report{
+		Seed:        7,
+		Columns:     []string{analysis.UtilizationTest, SimulationColumn},
+		Simulations: []string{SimulationColumn},
+		Rows: []Row{{
+			Utilization: 0.5,
+			TaskSets:    4,
+			Ratios:      map[string]float64{analysis.UtilizationTest: 1, SimulationColumn: 0.75},
+			Blocking:    map[string]float64{SimulationColumn: 1.5},
+			MissRate:    map[string]float64{SimulationColumn: 0.125},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"seed", "utilization", "task_sets", analysis.UtilizationTest, "simulation", "simulation-blocking", "simulation-miss-rate"},
+		{"7", "0.5", "4", "1.0000", "0.7500", "1.5000", "0.1250"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("CSV = %v, want %v", records, want)
+	}
+}
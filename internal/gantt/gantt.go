@@ -0,0 +1,326 @@
+package gantt
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// Layout of the chart, in pixels.
+const (
+	marginLeft  = 160
+	marginRight = 20
+	marginTop   = 40
+	laneHeight  = 40
+	axisHeight  = 30
+	legendRow   = 20
+	legendItem  = 120
+	barInset    = 10
+)
+
+// resourceColors are the colors shading critical sections, by resource.
+var resourceColors = []string{
+	"#1f77b4", "#d62728", "#2ca02c", "#9467bd", "#ff7f0e",
+	"#8c564b", "#e377c2", "#17becf", "#bcbd22", "#7f7f7f",
+}
+
+// Options controls the rendering of a chart.
+type Options struct {
+	Title string
+	// Width is the width of the chart in pixels.
+	Width int
+	// ResourceLanes adds one lane per resource showing which jobs hold its units.
+	ResourceLanes bool
+}
+
+// chart holds the state of a chart being rendered.
+type chart struct {
+	buf       bytes.Buffer
+	opts      Options
+	result    *scheduler.Result
+	taskSet   []*tasks.Task
+	resources []int
+	end       float64
+	scale     float64
+	height    int
+	legend    int
+}
+
+// Render writes the schedule of the simulation result as a standalone SVG Gantt
+// chart: one lane per task with its execution intervals, critical sections
+// shaded by resource, release (up) and deadline (down) arrows, and crosses for
+// dropped or aborted jobs. Intervals spent in Overrun mode are highlighted.
+func Render(w io.Writer, result *scheduler.Result, taskSet []*tasks.Task, opts Options) error {
+	c := newChart(result, taskSet, opts)
+	c.render()
+	_, err := w.Write(c.buf.Bytes())
+	return err
+}
+
+// RenderHTML writes the chart of Render embedded in a standalone HTML page.
+func RenderHTML(w io.Writer, result *scheduler.Result, taskSet []*tasks.Task, opts Options) error {
+	c := newChart(result, taskSet, opts)
+	c.render()
+
+	title := html.EscapeString(opts.Title)
+	page := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>body { font-family: sans-serif; margin: 20px; } svg { border: 1px solid #ddd; }</style>
+</head>
+<body>
+<h1>%s</h1>
+%s
+</body>
+</html>
+`, title, title, c.buf.String())
+	_, err := io.WriteString(w, page)
+	return err
+}
+
+func newChart(result *scheduler.Result, taskSet []*tasks.Task, opts Options) *chart {
+	if opts.Width <= 0 {
+		opts.Width = 1600
+	}
+
+	sorted := make([]*tasks.Task, len(taskSet))
+	copy(sorted, taskSet)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	c := &chart{opts: opts, result: result, taskSet: sorted, end: result.SimulateTime}
+	for _, s := range result.Schedule {
+		c.end = math.Max(c.end, s.EndTime)
+	}
+	if c.end <= 0 {
+		c.end = 1
+	}
+	c.scale = float64(opts.Width-marginLeft-marginRight) / c.end
+
+	ids := make(map[int]bool)
+	for _, t := range sorted {
+		for _, cs := range t.CriticalSections {
+			ids[cs.ResourceID] = true
+		}
+	}
+	for _, h := range result.Holds {
+		ids[h.ResourceID] = true
+	}
+	for id := range ids {
+		c.resources = append(c.resources, id)
+	}
+	sort.Ints(c.resources)
+
+	lanes := len(sorted)
+	if opts.ResourceLanes {
+		lanes += len(c.resources)
+	}
+	// The legend lists Overrun mode and the resources, wrapping over several rows.
+	perRow := (opts.Width - marginLeft - marginRight) / legendItem
+	if perRow < 1 {
+		perRow = 1
+	}
+	c.legend = ((len(c.resources)+perRow)/perRow)*legendRow + 10
+	c.height = marginTop + lanes*laneHeight + axisHeight + c.legend
+	return c
+}
+
+// x returns the horizontal position of the time instant.
+func (c *chart) x(t float64) float64 {
+	return marginLeft + t*c.scale
+}
+
+// laneTop returns the vertical position of the top of the lane.
+func laneTop(lane int) float64 {
+	return float64(marginTop + lane*laneHeight)
+}
+
+func (c *chart) printf(format string, args ...any) {
+	fmt.Fprintf(&c.buf, format, args...)
+}
+
+func (c *chart) render() {
+	c.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n",
+		c.opts.Width, c.height)
+	c.printf("<desc>Seed %d, task set seed %d</desc>\n", c.result.Seed, c.result.TaskSetSeed)
+	c.printf(`<rect width="100%%" height="100%%" fill="white"/>` + "\n")
+	if c.opts.Title != "" {
+		c.printf(`<text x="%d" y="24" font-size="16">%s</text>`+"\n", marginLeft, html.EscapeString(c.opts.Title))
+	}
+
+	c.renderOverrun()
+	for lane, t := range c.taskSet {
+		c.renderTask(lane, t)
+	}
+	if c.opts.ResourceLanes {
+		for i, id := range c.resources {
+			c.renderResource(len(c.taskSet)+i, id)
+		}
+	}
+	c.renderAxis()
+	c.renderLegend()
+	c.printf("</svg>\n")
+}
+
+// renderOverrun highlights the intervals spent in Overrun mode.
+func (c *chart) renderOverrun() {
+	top := laneTop(0)
+	bottom := float64(c.height - axisHeight - c.legend)
+	start := -1.0
+	for _, s := range c.result.ModeSwitches {
+		switch {
+		case s.To == scheduler.Overrun:
+			start = s.Time
+		case start >= 0:
+			c.overrunRect(start, s.Time, top, bottom)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		c.overrunRect(start, c.end, top, bottom)
+	}
+}
+
+func (c *chart) overrunRect(from, to, top, bottom float64) {
+	c.printf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="#ffd6d6"><title>Overrun %.3f-%.3f</title></rect>`+"\n",
+		c.x(from), top, (to-from)*c.scale, bottom-top, from, to)
+}
+
+// renderTask draws the lane of a task.
+func (c *chart) renderTask(lane int, t *tasks.Task) {
+	top := laneTop(lane)
+	bottom := top + laneHeight
+	c.printf(`<line x1="%d" y1="%.2f" x2="%d" y2="%.2f" stroke="#ccc"/>`+"\n", marginLeft, bottom, c.opts.Width-marginRight, bottom)
+	label := fmt.Sprintf("Task %d (%v)", t.ID, t.Criticality)
+	if t.Name != "" {
+		label = fmt.Sprintf("%s (%v)", t.Name, t.Criticality)
+	}
+	c.printf(`<text x="8" y="%.2f" dominant-baseline="middle">%s</text>`+"\n", top+laneHeight/2, html.EscapeString(label))
+
+	color := taskColor(t.ID)
+	for _, s := range c.result.Schedule {
+		if s.TaskID != t.ID {
+			continue
+		}
+		c.printf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%d" fill="%s" stroke="black" stroke-width="0.5"><title>Job %d: %.3f-%.3f</title></rect>`+"\n",
+			c.x(s.StartTime), top+barInset, (s.EndTime-s.StartTime)*c.scale, laneHeight-2*barInset, color, s.JobID, s.StartTime, s.EndTime)
+
+		// Shade the parts of the interval spent in critical sections.
+		for _, h := range c.result.Holds {
+			if h.JobID != s.JobID {
+				continue
+			}
+			from, to := math.Max(h.Start, s.StartTime), math.Min(h.End, s.EndTime)
+			if to <= from {
+				continue
+			}
+			c.printf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%d" fill="%s" fill-opacity="0.6"><title>Job %d holds %d units of Resource %d</title></rect>`+"\n",
+				c.x(from), top+barInset, (to-from)*c.scale, laneHeight-2*barInset, resourceColor(h.ResourceID), h.JobID, h.Units, h.ResourceID)
+		}
+	}
+
+	for _, job := range c.result.Jobs {
+		if job.TaskID != t.ID {
+			continue
+		}
+		c.arrow(job.ReleaseTime, top, bottom, true, "black", fmt.Sprintf("Job %d released at %.3f", job.JobID, job.ReleaseTime))
+		if job.Deadline <= c.end {
+			color := "black"
+			if job.Missed {
+				color = "red"
+			}
+			c.arrow(job.Deadline, top, bottom, false, color, fmt.Sprintf("Job %d deadline at %.3f", job.JobID, job.Deadline))
+		}
+		if job.Outcome == scheduler.OutcomeDropped || job.Outcome == scheduler.OutcomeAborted {
+			c.printf(`<text x="%.2f" y="%.2f" fill="red" font-size="16" text-anchor="middle" dominant-baseline="middle">×<title>Job %d %s at %.3f</title></text>`+"\n",
+				c.x(job.FinishTime), top+laneHeight/2, job.JobID, job.Outcome, job.FinishTime)
+		}
+	}
+}
+
+// arrow draws a release (up) or deadline (down) arrow at the time instant.
+func (c *chart) arrow(t, top, bottom float64, up bool, color, title string) {
+	x := c.x(t)
+	tip, base := top+2, top+8
+	if !up {
+		tip, base = bottom-2, bottom-8
+	}
+	c.printf(`<g stroke="%s" fill="%s"><title>%s</title><line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f"/><path d="M%.2f %.2f L%.2f %.2f L%.2f %.2f Z"/></g>`+"\n",
+		color, color, title, x, top+2, x, bottom-2, x, tip, x-3, base, x+3, base)
+}
+
+// renderResource draws the lane of a resource with the jobs holding its units.
+func (c *chart) renderResource(lane int, resourceID int) {
+	top := laneTop(lane)
+	bottom := top + laneHeight
+	c.printf(`<line x1="%d" y1="%.2f" x2="%d" y2="%.2f" stroke="#ccc"/>`+"\n", marginLeft, bottom, c.opts.Width-marginRight, bottom)
+	c.printf(`<text x="8" y="%.2f" dominant-baseline="middle" fill="%s">Resource %d</text>`+"\n", top+laneHeight/2, resourceColor(resourceID), resourceID)
+
+	for _, h := range c.result.Holds {
+		if h.ResourceID != resourceID || h.End <= h.Start {
+			continue
+		}
+		c.printf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%d" fill="%s" stroke="%s"><title>Job %d (Task %d) holds %d units: %.3f-%.3f</title></rect>`+"\n",
+			c.x(h.Start), top+barInset, (h.End-h.Start)*c.scale, laneHeight-2*barInset, taskColor(h.TaskID), resourceColor(resourceID),
+			h.JobID, h.TaskID, h.Units, h.Start, h.End)
+	}
+}
+
+// renderAxis draws the time axis with evenly spaced ticks.
+func (c *chart) renderAxis() {
+	y := float64(c.height - axisHeight - c.legend)
+	c.printf(`<line x1="%d" y1="%.2f" x2="%d" y2="%.2f" stroke="black"/>`+"\n", marginLeft, y, c.opts.Width-marginRight, y)
+
+	step := tickStep(c.end)
+	for t := 0.0; t <= c.end+step/1000; t += step {
+		c.printf(`<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="black"/>`+"\n", c.x(t), y, c.x(t), y+5)
+		c.printf(`<text x="%.2f" y="%.2f" text-anchor="middle">%g</text>`+"\n", c.x(t), y+18, t)
+	}
+}
+
+// renderLegend draws the colors of Overrun mode and of the resources.
+func (c *chart) renderLegend() {
+	x, y := float64(marginLeft), float64(c.height-c.legend+10)
+	item := func(color, label string) {
+		if x+legendItem > float64(c.opts.Width-marginRight) && x > marginLeft {
+			x, y = marginLeft, y+legendRow
+		}
+		c.printf(`<rect x="%.2f" y="%.2f" width="12" height="12" fill="%s"/><text x="%.2f" y="%.2f">%s</text>`+"\n",
+			x, y, color, x+16, y+10, label)
+		x += legendItem
+	}
+
+	item("#ffd6d6", "Overrun mode")
+	for _, id := range c.resources {
+		item(resourceColor(id), fmt.Sprintf("Resource %d", id))
+	}
+}
+
+// tickStep returns a round step giving about ten ticks over the time span.
+func tickStep(span float64) float64 {
+	raw := span / 10
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, factor := range []float64{1, 2, 5} {
+		if raw <= factor*magnitude {
+			return factor * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// taskColor returns a light color for the task, spreading hues by the golden angle.
+func taskColor(taskID int) string {
+	return fmt.Sprintf("hsl(%.0f, 60%%, 75%%)", math.Mod(float64(taskID)*137.508, 360))
+}
+
+// resourceColor returns the color of the resource.
+func resourceColor(resourceID int) string {
+	return resourceColors[(resourceID-1+len(resourceColors))%len(resourceColors)]
+}
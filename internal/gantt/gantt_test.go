@@ -0,0 +1,114 @@
+package gantt
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// titles parses the SVG document and returns the titles of its elements.
+func titles(t *testing.T, svg []byte) []string {
+	t.Helper()
+	var list []string
+	decoder := xml.NewDecoder(bytes.NewReader(svg))
+	inTitle := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return list
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			inTitle = token.Name.Local == "title"
+		case xml.CharData:
+			if inTitle {
+				list = append(list, string(token))
+			}
+		case xml.EndElement:
+			inTitle = false
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	taskSet := []*tasks.Task{
+		{ID: 2, Criticality: tasks.HC, Period: 10, Deadline: 10},
+		{ID: 1, Name: "brakes & co", Criticality: tasks.LC, Period: 5, Deadline: 5},
+	}
+	result := &scheduler.Result{
+		SimulateTime: 10,
+		Schedule: []scheduler.Schedule{
+			{TaskID: 1, JobID: 1, StartTime: 0, EndTime: 1},
+			{TaskID: 2, JobID: 2, StartTime: 1, EndTime: 4},
+		},
+		ModeSwitches: []scheduler.ModeSwitch{{Time: 3, From: scheduler.Normal, To: scheduler.Overrun}},
+		Jobs: []scheduler.JobRecord{
+			{JobID: 1, TaskID: 1, ReleaseTime: 0, Deadline: 5, FinishTime: 1, Outcome: scheduler.OutcomeCompleted},
+			{JobID: 2, TaskID: 2, ReleaseTime: 0, Deadline: 10, FinishTime: 4, Outcome: scheduler.OutcomeCompleted},
+			{JobID: 3, TaskID: 1, ReleaseTime: 5, Deadline: 10, FinishTime: 5, Outcome: scheduler.OutcomeDropped},
+		},
+		Holds: []scheduler.Hold{{JobID: 2, TaskID: 2, ResourceID: 4, Units: 2, Start: 2, End: 3}},
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, result, taskSet, Options{Width: 800, ResourceLanes: true}); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(titles(t, buf.Bytes()), "\n")
+	for _, want := range []string{
+		"Job 1: 0.000-1.000",
+		"Job 2: 1.000-4.000",
+		"Job 2 holds 2 units of Resource 4",
+		"Job 2 (Task 2) holds 2 units: 2.000-3.000",
+		"Overrun 3.000-10.000",
+		"Job 3 released at 5.000",
+		"Job 3 deadline at 10.000",
+		"Job 3 dropped at 5.000",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("chart has no %q element", want)
+		}
+	}
+
+	// Lanes are sorted by task ID and labelled with the task names.
+	svg := buf.String()
+	if i, j := strings.Index(svg, "brakes &amp; co (LC)"), strings.Index(svg, "Task 2 (HC)"); i < 0 || j < 0 || i > j {
+		t.Errorf("task lanes are not labelled in task ID order")
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderHTML(&buf, &scheduler.Result{SimulateTime: 10}, nil, Options{Title: "<FMS>"}); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	if !strings.HasPrefix(page, "<!DOCTYPE html>") || !strings.Contains(page, "<h1>&lt;FMS&gt;</h1>") || !strings.Contains(page, "<svg") {
+		t.Errorf("unexpected page:\n%s", page)
+	}
+}
+
+func TestTickStep(t *testing.T) {
+	tests := []struct {
+		span, step float64
+	}{
+		{span: 1000, step: 100},
+		{span: 30, step: 5},
+		{span: 15, step: 2},
+		{span: 1, step: 0.1},
+		{span: 700, step: 100},
+	}
+	for _, tt := range tests {
+		if step := tickStep(tt.span); step != tt.step {
+			t.Errorf("tickStep(%g) = %g, want %g", tt.span, step, tt.step)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// Stats summarises a sample of values. Percentiles use the nearest-rank method.
+type Stats struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+}
+
+// JobMetrics holds the timing metrics of a completed job. FinishJitter is the
+// response time of the job in excess of the best response time of its task.
+type JobMetrics struct {
+	JobID        int     `json:"job_id"`
+	TaskID       int     `json:"task_id"`
+	ResponseTime float64 `json:"response_time"`
+	StartLatency float64 `json:"start_latency"`
+	FinishJitter float64 `json:"finish_jitter"`
+	Preemptions  int     `json:"preemptions"`
+	BlockingTime float64 `json:"blocking_time"`
+}
+
+// TaskMetrics summarises the metrics of the completed jobs of a task.
+type TaskMetrics struct {
+	TaskID       int                    `json:"task_id"`
+	Criticality  tasks.CriticalityLevel `json:"criticality"`
+	Jobs         int                    `json:"jobs"`
+	ResponseTime Stats                  `json:"response_time"`
+	StartLatency Stats                  `json:"start_latency"`
+	FinishJitter Stats                  `json:"finish_jitter"`
+	Preemptions  Stats                  `json:"preemptions"`
+	BlockingTime Stats                  `json:"blocking_time"`
+}
+
+// ModeCompletion counts the LC jobs released in a mode by outcome. Jobs still
+// pending at the end of the simulation are left out. Suppressed and Skipped
+// count the LC releases in the mode at which no job was released, because LC
+// tasks are suspended in Overrun mode or to catch up after a deadline miss.
+// Ratio is the share of the counted jobs and releases without a job that
+// completed by their deadline, so that shedding LC work does not improve it.
+type ModeCompletion struct {
+	Mode       scheduler.Mode `json:"mode"`
+	Jobs       int            `json:"jobs"`
+	OnTime     int            `json:"on_time"`
+	Late       int            `json:"late"`
+	Dropped    int            `json:"dropped"`
+	Aborted    int            `json:"aborted"`
+	Suppressed int            `json:"suppressed"`
+	Skipped    int            `json:"skipped"`
+	Ratio      float64        `json:"ratio"`
+}
+
+// Report holds the metrics of a simulation run, with the seeds of the run.
+type Report struct {
+	Seed         int64            `json:"seed"`
+	TaskSetSeed  int64            `json:"task_set_seed,omitempty"`
+	Jobs         []JobMetrics     `json:"jobs"`
+	Tasks        []TaskMetrics    `json:"tasks"`
+	LCCompletion []ModeCompletion `json:"lc_completion"`
+}
+
+// Compute derives the metrics from the job records of a simulation result.
+func Compute(result *scheduler.Result) *Report {
+	report := &Report{
+		Seed:        result.Seed,
+		TaskSetSeed: result.TaskSetSeed,
+		Jobs:        make([]JobMetrics, 0),
+		Tasks:       make([]TaskMetrics, 0),
+	}
+
+	byTask := make(map[int][]int)
+	criticality := make(map[int]tasks.CriticalityLevel)
+	for _, job := range result.Jobs {
+		criticality[job.TaskID] = job.Criticality
+		if job.Outcome != scheduler.OutcomeCompleted {
+			continue
+		}
+		byTask[job.TaskID] = append(byTask[job.TaskID], len(report.Jobs))
+		report.Jobs = append(report.Jobs, JobMetrics{
+			JobID:        job.JobID,
+			TaskID:       job.TaskID,
+			ResponseTime: job.FinishTime - job.ReleaseTime,
+			StartLatency: job.StartTime - job.ReleaseTime,
+			Preemptions:  job.Preemptions,
+			BlockingTime: job.BlockingTime,
+		})
+	}
+
+	taskIDs := make([]int, 0, len(byTask))
+	for id := range byTask {
+		taskIDs = append(taskIDs, id)
+	}
+	sort.Ints(taskIDs)
+
+	for _, id := range taskIDs {
+		indices := byTask[id]
+		best := math.Inf(1)
+		for _, i := range indices {
+			best = math.Min(best, report.Jobs[i].ResponseTime)
+		}
+
+		var response, latency, jitter, preemptions, blocking []float64
+		for _, i := range indices {
+			job := &report.Jobs[i]
+			job.FinishJitter = job.ResponseTime - best
+			response = append(response, job.ResponseTime)
+			latency = append(latency, job.StartLatency)
+			jitter = append(jitter, job.FinishJitter)
+			preemptions = append(preemptions, float64(job.Preemptions))
+			blocking = append(blocking, job.BlockingTime)
+		}
+
+		report.Tasks = append(report.Tasks, TaskMetrics{
+			TaskID:       id,
+			Criticality:  criticality[id],
+			Jobs:         len(indices),
+			ResponseTime: summarize(response),
+			StartLatency: summarize(latency),
+			FinishJitter: summarize(jitter),
+			Preemptions:  summarize(preemptions),
+			BlockingTime: summarize(blocking),
+		})
+	}
+
+	report.LCCompletion = lcCompletion(result)
+	return report
+}
+
+// lcCompletion counts the outcomes of LC jobs, and the LC releases suppressed or
+// skipped, by the mode they were released in.
+func lcCompletion(result *scheduler.Result) []ModeCompletion {
+	modes := []ModeCompletion{{Mode: scheduler.Normal}, {Mode: scheduler.Overrun}}
+	criticality := make(map[int]tasks.CriticalityLevel)
+	for _, job := range result.Jobs {
+		criticality[job.TaskID] = job.Criticality
+		if job.Criticality != tasks.LC || job.Outcome == scheduler.OutcomeUnfinished {
+			continue
+		}
+		m := &modes[job.ReleaseMode]
+		m.Jobs++
+		switch {
+		case job.Outcome == scheduler.OutcomeDropped:
+			m.Dropped++
+		case job.Outcome == scheduler.OutcomeAborted:
+			m.Aborted++
+		case job.Missed:
+			m.Late++
+		default:
+			m.OnTime++
+		}
+	}
+	for _, s := range result.Suppressed {
+		modes[s.Mode].Suppressed++
+	}
+	for _, s := range result.Skipped {
+		if criticality[s.TaskID] == tasks.LC {
+			modes[s.Mode].Skipped++
+		}
+	}
+	for i := range modes {
+		if total := modes[i].Jobs + modes[i].Suppressed + modes[i].Skipped; total > 0 {
+			modes[i].Ratio = float64(modes[i].OnTime) / float64(total)
+		}
+	}
+	return modes
+}
+
+// summarize computes the statistics of the values.
+func summarize(values []float64) Stats {
+	if len(values) == 0 {
+		return Stats{}
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	return Stats{
+		Min: sorted[0],
+		Avg: sum / float64(len(sorted)),
+		Max: sorted[len(sorted)-1],
+		P50: percentile(sorted, 50),
+		P90: percentile(sorted, 90),
+		P99: percentile(sorted, 99),
+	}
+}
+
+// percentile returns the p-th percentile of the sorted values by nearest rank.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// WriteTable writes the per-task metrics and the LC completion ratios as text tables.
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Task\tCrit\tJobs\tResp min\tResp avg\tResp max\tResp p90\tResp p99\tLatency avg\tLatency max\tJitter max\tPreempt avg\tPreempt max\tBlock avg\tBlock max\t")
+	for _, t := range r.Tasks {
+		fmt.Fprintf(tw, "%d\t%v\t%d\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.2f\t%.0f\t%.3f\t%.3f\t\n",
+			t.TaskID, t.Criticality, t.Jobs,
+			t.ResponseTime.Min, t.ResponseTime.Avg, t.ResponseTime.Max, t.ResponseTime.P90, t.ResponseTime.P99,
+			t.StartLatency.Avg, t.StartLatency.Max, t.FinishJitter.Max,
+			t.Preemptions.Avg, t.Preemptions.Max, t.BlockingTime.Avg, t.BlockingTime.Max)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "LC jobs released in\tJobs\tOn time\tLate\tDropped\tAborted\tSuppressed\tSkipped\tRatio\t")
+	for _, m := range r.LCCompletion {
+		fmt.Fprintf(tw, "%v\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%.3f\t\n", m.Mode, m.Jobs, m.OnTime, m.Late, m.Dropped, m.Aborted, m.Suppressed, m.Skipped, m.Ratio)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// completed returns the record of a job of the task completed on time.
+func completed(jobID, taskID int, criticality tasks.CriticalityLevel, release, start, finish float64) scheduler.JobRecord {
+	return scheduler.JobRecord{
+		JobID: jobID, TaskID: taskID, Criticality: criticality, ReleaseTime: release, Deadline: release + 10,
+		Started: true, StartTime: start, FinishTime: finish, Outcome: scheduler.OutcomeCompleted,
+	}
+}
+
+func TestCompute(t *testing.T) {
+	late := completed(4, 2, tasks.LC, 10, 12, 21)
+	late.Missed = true
+	dropped := scheduler.JobRecord{JobID: 5, TaskID: 2, Criticality: tasks.LC, ReleaseTime: 20, Outcome: scheduler.OutcomeDropped}
+	unfinished := scheduler.JobRecord{JobID: 6, TaskID: 2, Criticality: tasks.LC, ReleaseMode: scheduler.Overrun, ReleaseTime: 30,
+		Outcome: scheduler.OutcomeUnfinished}
+	result := &scheduler.Result{
+		Seed: 7,
+		Jobs: []scheduler.JobRecord{
+			completed(1, 1, tasks.HC, 0, 0, 2),
+			completed(2, 2, tasks.LC, 0, 2, 3),
+			completed(3, 1, tasks.HC, 10, 11, 15),
+			late, dropped, unfinished,
+		},
+		Suppressed: []scheduler.Suppression{{Time: 40, TaskID: 2, Mode: scheduler.Overrun}},
+		Skipped: []scheduler.Suppression{
+			{Time: 30, TaskID: 2, Mode: scheduler.Normal},
+			{Time: 50, TaskID: 2, Mode: scheduler.Overrun},
+			{Time: 50, TaskID: 1, Mode: scheduler.Overrun},
+		},
+	}
+	report := Compute(result)
+
+	// Only completed jobs have metrics. The finish jitter is the response time in
+	// excess of the best one of the task: 2 for task 1 and 3 for task 2.
+	jobs := []JobMetrics{
+		{JobID: 1, TaskID: 1, ResponseTime: 2, StartLatency: 0, FinishJitter: 0},
+		{JobID: 2, TaskID: 2, ResponseTime: 3, StartLatency: 2, FinishJitter: 0},
+		{JobID: 3, TaskID: 1, ResponseTime: 5, StartLatency: 1, FinishJitter: 3},
+		{JobID: 4, TaskID: 2, ResponseTime: 11, StartLatency: 2, FinishJitter: 8},
+	}
+	if !reflect.DeepEqual(report.Jobs, jobs) {
+		t.Errorf("jobs = %+v, want %+v", report.Jobs, jobs)
+	}
+
+	if len(report.Tasks) != 2 {
+		t.Fatalf("%d tasks, want 2", len(report.Tasks))
+	}
+	task := report.Tasks[0]
+	response := Stats{Min: 2, Avg: 3.5, Max: 5, P50: 2, P90: 5, P99: 5}
+	if task.TaskID != 1 || task.Criticality != tasks.HC || task.Jobs != 2 || task.ResponseTime != response {
+		t.Errorf("task 1 = %+v, want 2 HC jobs with response times %+v", task, response)
+	}
+	if task.FinishJitter.Max != 3 {
+		t.Errorf("task 1 finish jitter = %+v, want max 3", task.FinishJitter)
+	}
+
+	// Suppressed and skipped LC releases count against the completion ratio of
+	// the mode they happened in, like dropped and late jobs. The unfinished job
+	// and the skipped release of HC task 1 are left out.
+	completion := []ModeCompletion{
+		{Mode: scheduler.Normal, Jobs: 3, OnTime: 1, Late: 1, Dropped: 1, Skipped: 1, Ratio: 0.25},
+		{Mode: scheduler.Overrun, Suppressed: 1, Skipped: 1},
+	}
+	if !reflect.DeepEqual(report.LCCompletion, completion) {
+		t.Errorf("LC completion = %+v, want %+v", report.LCCompletion, completion)
+	}
+	if report.Seed != 7 {
+		t.Errorf("seed = %d, want 7", report.Seed)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	values := make([]float64, 0, 100)
+	for v := 100; v >= 1; v-- {
+		values = append(values, float64(v))
+	}
+
+	want := Stats{Min: 1, Avg: 50.5, Max: 100, P50: 50, P90: 90, P99: 99}
+	if got := summarize(values); got != want {
+		t.Errorf("summarize() = %+v, want %+v", got, want)
+	}
+	if values[0] != 100 {
+		t.Error("summarize() sorted the values in place")
+	}
+	if got := summarize(nil); got != (Stats{}) {
+		t.Errorf("summarize(nil) = %+v, want zero", got)
+	}
+}
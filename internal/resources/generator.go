@@ -1,11 +1,19 @@
 package resources
 
-// GenerateResources creates a list of resource IDs.
-func GenerateResources(numResources int) []*Resource {
-	resources := make([]*Resource, numResources)
-	for i := 0; i < numResources; i++ {
+import (
+	"math/rand"
+
+	"github.com/99109766/fms-scheduler/config"
+)
+
+// GenerateResources creates a list of resources, each with a random number of
+// units drawn from the configured range.
+func GenerateResources(cfg *config.Config, rng *rand.Rand) []*Resource {
+	resources := make([]*Resource, cfg.NumResources)
+	for i := 0; i < cfg.NumResources; i++ {
 		resources[i] = &Resource{
 			ID:            i + 1,
+			Units:         cfg.ResourceUnits[0] + rng.Intn(cfg.ResourceUnits[1]-cfg.ResourceUnits[0]+1),
 			AssignedTasks: make([]int, 0),
 		}
 	}
@@ -1,13 +1,31 @@
 package resources
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
 
 type Resource struct {
-	ID            int   `json:"id"`
-	AssignedTasks []int `json:"assigned_tasks"`
-	Ceiling       int   `json:"ceiling"`
+	ID            int    `json:"id"`
+	Name          string `json:"name,omitempty"`
+	Units         int    `json:"units"`
+	AssignedTasks []int  `json:"assigned_tasks"`
+	Ceiling       int    `json:"ceiling"`
+	Ceilings      []int  `json:"ceilings"`
+}
+
+// CeilingAt returns the ceiling of the resource when the given number of units
+// is available. Lower values mean higher preemption levels.
+func (r *Resource) CeilingAt(available int) int {
+	if available < 0 {
+		available = 0
+	}
+	if available >= len(r.Ceilings) {
+		return math.MaxInt32
+	}
+	return r.Ceilings[available]
 }
 
 func (r Resource) String() string {
-	return fmt.Sprintf("Resource %d -> Tasks: %v, Ceiling: %d", r.ID, r.AssignedTasks, r.Ceiling)
+	return fmt.Sprintf("Resource %d (%d units) -> Tasks: %v, Ceiling: %d", r.ID, r.Units, r.AssignedTasks, r.Ceiling)
 }
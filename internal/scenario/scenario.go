@@ -0,0 +1,101 @@
+package scenario
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of injected events. The budget of a job is WCET1 for LC jobs and
+// WCET1+WCET2 for HC jobs. A simulation rejects the injections its budget cannot
+// honour: overruns of LC jobs, execution times above the budget and hold
+// extensions pushing a critical section past it. A hold extension added to a
+// drawn execution time is capped at the budget, and the report records the cap.
+const (
+	// ExecutionTime sets the actual execution time of the job to ExecTime.
+	ExecutionTime = "execution"
+	// Overrun makes the job execute its WCET1 increased by the Overrun fraction.
+	Overrun = "overrun"
+	// DelayedRelease releases the job Delay time units late. Later jobs of the
+	// task are released relative to the delayed release, as for a sporadic task.
+	DelayedRelease = "delay"
+	// HoldExtension makes the job hold the resource Extension time units longer,
+	// executing that much longer inside its critical section.
+	HoldExtension = "hold"
+)
+
+// Scenario is a script of events injected into a simulation run.
+type Scenario struct {
+	Name       string      `yaml:"name" json:"name"`
+	Injections []Injection `yaml:"injections" json:"injections" validate:"dive"`
+}
+
+// Injection is an event injected into one job. The job is the Job-th job of the
+// task (starting at 1) or, if Job is 0, the first job of the task released at or
+// after time At.
+type Injection struct {
+	Kind   string  `yaml:"kind" json:"kind" validate:"oneof=execution overrun delay hold"`
+	TaskID int     `yaml:"task" json:"task" validate:"min=1"`
+	Job    int     `yaml:"job" json:"job,omitempty" validate:"min=0"`
+	At     float64 `yaml:"at" json:"at,omitempty" validate:"min=0"`
+
+	ExecTime   float64 `yaml:"exec_time" json:"exec_time,omitempty" validate:"min=0,required_if=Kind execution"`
+	Overrun    float64 `yaml:"overrun" json:"overrun,omitempty" validate:"min=0,required_if=Kind overrun"`
+	Delay      float64 `yaml:"delay" json:"delay,omitempty" validate:"min=0,required_if=Kind delay"`
+	ResourceID int     `yaml:"resource" json:"resource,omitempty" validate:"min=0,required_if=Kind hold"`
+	Extension  float64 `yaml:"extension" json:"extension,omitempty" validate:"min=0,required_if=Kind hold"`
+}
+
+// Matches reports whether the injection targets the index-th job of the task,
+// released at the given time.
+func (i Injection) Matches(taskID, index int, releaseTime float64) bool {
+	if i.TaskID != taskID {
+		return false
+	}
+	if i.Job > 0 {
+		return i.Job == index
+	}
+	return releaseTime >= i.At
+}
+
+func (i Injection) String() string {
+	target := fmt.Sprintf("job %d of task %d", i.Job, i.TaskID)
+	if i.Job == 0 {
+		target = fmt.Sprintf("first job of task %d at t>=%.3f", i.TaskID, i.At)
+	}
+
+	switch i.Kind {
+	case ExecutionTime:
+		return fmt.Sprintf("%s executes %.3f", target, i.ExecTime)
+	case Overrun:
+		return fmt.Sprintf("%s overruns WCET1 by %.0f%%", target, i.Overrun*100)
+	case DelayedRelease:
+		return fmt.Sprintf("%s is released %.3f late", target, i.Delay)
+	case HoldExtension:
+		return fmt.Sprintf("%s holds resource %d %.3f longer", target, i.ResourceID, i.Extension)
+	default:
+		return target
+	}
+}
+
+// LoadScenario reads a scenario from a YAML or JSON file.
+func LoadScenario(filePath string) (*Scenario, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON documents are valid YAML, so a single decoder handles both formats.
+	var scenario Scenario
+	if err := yaml.Unmarshal(data, &scenario); err != nil {
+		return nil, err
+	}
+
+	if err := validator.New().Struct(scenario); err != nil {
+		return nil, err
+	}
+
+	return &scenario, nil
+}
@@ -0,0 +1,103 @@
+package scenario
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadScenario(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		data     string
+		scenario *Scenario
+	}{
+		{
+			name: "yaml",
+			file: "scenario.yaml",
+			data: `name: story
+injections:
+  - kind: overrun
+    task: 2
+    job: 3
+    overrun: 0.5
+  - kind: delay
+    task: 1
+    at: 100
+    delay: 2.5
+`,
+			scenario: &Scenario{Name: "story", Injections: []Injection{
+				{Kind: Overrun, TaskID: 2, Job: 3, Overrun: 0.5},
+				{Kind: DelayedRelease, TaskID: 1, At: 100, Delay: 2.5},
+			}},
+		},
+		{
+			name: "json",
+			file: "scenario.json",
+			data: `{"injections": [{"kind": "hold", "task": 3, "job": 1, "resource": 2, "extension": 2}]}`,
+			scenario: &Scenario{Injections: []Injection{
+				{Kind: HoldExtension, TaskID: 3, Job: 1, ResourceID: 2, Extension: 2},
+			}},
+		},
+		{
+			name: "unknown kind",
+			file: "scenario.yaml",
+			data: "injections:\n  - kind: crash\n    task: 1\n",
+		},
+		{
+			name: "missing parameter",
+			file: "scenario.yaml",
+			data: "injections:\n  - kind: execution\n    task: 1\n    job: 1\n",
+		},
+		{
+			name: "no task",
+			file: "scenario.yaml",
+			data: "injections:\n  - kind: delay\n    delay: 1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			sc, err := LoadScenario(path)
+			if (err == nil) != (tt.scenario != nil) {
+				t.Fatalf("LoadScenario() error = %v, want valid %v", err, tt.scenario != nil)
+			}
+			if tt.scenario != nil && !reflect.DeepEqual(sc, tt.scenario) {
+				t.Errorf("LoadScenario() = %+v, want %+v", sc, tt.scenario)
+			}
+		})
+	}
+}
+
+func TestInjectionMatches(t *testing.T) {
+	byIndex := Injection{Kind: Overrun, TaskID: 2, Job: 3}
+	byTime := Injection{Kind: Overrun, TaskID: 2, At: 250}
+
+	tests := []struct {
+		injection   Injection
+		taskID      int
+		index       int
+		releaseTime float64
+		matches     bool
+	}{
+		{injection: byIndex, taskID: 2, index: 3, releaseTime: 0, matches: true},
+		{injection: byIndex, taskID: 2, index: 4, releaseTime: 1000},
+		{injection: byIndex, taskID: 1, index: 3},
+		{injection: byTime, taskID: 2, index: 1, releaseTime: 250, matches: true},
+		{injection: byTime, taskID: 2, index: 9, releaseTime: 400, matches: true},
+		{injection: byTime, taskID: 2, index: 1, releaseTime: 200},
+	}
+
+	for _, tt := range tests {
+		if got := tt.injection.Matches(tt.taskID, tt.index, tt.releaseTime); got != tt.matches {
+			t.Errorf("%v: Matches(%d, %d, %g) = %v, want %v", tt.injection, tt.taskID, tt.index, tt.releaseTime, got, tt.matches)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/99109766/fms-scheduler/internal/trace"
+)
+
+// Deadlock describes jobs found waiting for each other: the job JobIDs[i] of
+// task TaskIDs[i] waits for resource ResourceIDs[i], whose units are all held
+// by jobs of the deadlock. The jobs stay blocked until they are aborted.
+type Deadlock struct {
+	Time        float64 `json:"time"`
+	JobIDs      []int   `json:"job_ids"`
+	TaskIDs     []int   `json:"task_ids"`
+	ResourceIDs []int   `json:"resource_ids"`
+}
+
+func (d Deadlock) String() string {
+	return fmt.Sprintf("Time %.3f: %s", d.Time, d.chain())
+}
+
+// chain lists the jobs of the deadlock and the resources they wait for.
+func (d Deadlock) chain() string {
+	chain := make([]string, len(d.JobIDs))
+	for i := range d.JobIDs {
+		chain[i] = fmt.Sprintf("Job %d (Task %d) waits for Resource %d", d.JobIDs[i], d.TaskIDs[i], d.ResourceIDs[i])
+	}
+	return strings.Join(chain, ", ")
+}
+
+// detectDeadlock checks whether the job, which just blocked, is deadlocked: the
+// holders of the resource it waits for are all blocked, and so are the holders
+// of the resources they wait for, recursively. No job outside this set can then
+// release the units they wait for. A deadlock is reported once.
+func (s *simulator) detectDeadlock(job *Job) {
+	blocked := make(map[*Job]bool)
+	for _, other := range s.blockedJobs {
+		blocked[other] = true
+	}
+
+	members := []*Job{job}
+	found := map[*Job]bool{job: true}
+	for i := 0; i < len(members); i++ {
+		waiting := members[i]
+		if waiting.waitFor == 0 || waiting.deadlocked {
+			return
+		}
+		holders := s.holders(waiting.waitFor)
+		if len(holders) == 0 {
+			return
+		}
+		for _, holder := range holders {
+			if !blocked[holder] {
+				return
+			}
+			if !found[holder] {
+				found[holder] = true
+				members = append(members, holder)
+			}
+		}
+	}
+
+	d := Deadlock{Time: s.currentTime}
+	for _, member := range members {
+		member.deadlocked = true
+		d.JobIDs = append(d.JobIDs, member.JobID)
+		d.TaskIDs = append(d.TaskIDs, member.Task.ID)
+		d.ResourceIDs = append(d.ResourceIDs, member.waitFor)
+	}
+	s.deadlocks = append(s.deadlocks, d)
+
+	e := jobEvent(trace.Deadlock, job)
+	e.ResourceID = job.waitFor
+	e.Message = d.chain()
+	s.emit(e)
+}
+
+// holders returns the pending jobs holding units of the resource.
+func (s *simulator) holders(resourceID int) []*Job {
+	var holders []*Job
+	pending := append([]*Job{s.runningJob}, s.readyQueue...)
+	for _, job := range append(pending, s.blockedJobs...) {
+		if job != nil && job.held[resourceID] > 0 {
+			holders = append(holders, job)
+		}
+	}
+	return holders
+}
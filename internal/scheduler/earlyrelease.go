@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"github.com/99109766/fms-scheduler/internal/tasks"
+	"github.com/99109766/fms-scheduler/internal/trace"
+)
+
+// elasticDeadline returns the relative deadline of an LC task in Overrun mode,
+// stretched by the same ratio as its period.
+func (s *simulator) elasticDeadline(t *tasks.Task) float64 {
+	return t.Deadline * s.lcMaxPeriodRatio
+}
+
+// releaseElastic handles a release event of an LC task in Overrun mode. At the
+// end of its extended period the task is released unconditionally. Earlier, from
+// its nominal period on, it is released early only if the reclaimed slack covers
+// its WCET; otherwise it waits for more slack or for the extended period.
+func (s *simulator) releaseElastic(t *tasks.Task, releaseTime float64) {
+	extendedRelease := s.lastRelease[t.ID] + t.Period*s.lcMaxPeriodRatio
+	if releaseTime >= extendedRelease-epsilon {
+		s.release(t, releaseTime)
+		return
+	}
+
+	if s.slack >= t.WCET1 {
+		s.releaseEarly(t)
+		return
+	}
+
+	s.pendingEarly[t.ID] = true
+	s.pushEvent(&event{time: extendedRelease, kind: releaseEvent, task: t, epoch: s.releaseEpoch[t.ID]})
+}
+
+// releaseEarly releases a job of the LC task now, paying its WCET from the slack.
+func (s *simulator) releaseEarly(t *tasks.Task) {
+	s.slack -= t.WCET1
+	s.emit(trace.Event{Kind: trace.EarlyRelease, TaskID: t.ID, Slack: s.slack})
+	s.release(t, s.currentTime)
+}
+
+// reclaimSlack adds the unused budget of a completed HC job to the slack and
+// releases the LC tasks waiting for it.
+func (s *simulator) reclaimSlack(job *Job) {
+	if unused := job.Budget - job.ExecTime; unused > epsilon {
+		s.slack += unused
+		e := jobEvent(trace.Reclaim, job)
+		e.Amount, e.Slack = unused, s.slack
+		s.emit(e)
+	}
+
+	for _, t := range s.taskSet {
+		if s.pendingEarly[t.ID] && s.slack >= t.WCET1 {
+			s.releaseEarly(t)
+		}
+	}
+}
+
+// extendLCDeadlines stretches the deadlines of pending LC jobs to their elastic
+// deadlines. This is used instead of dropping them when the system switches to
+// Overrun mode under ER-EDF.
+func (s *simulator) extendLCDeadlines(jobs []*Job) {
+	for _, job := range jobs {
+		if job.Task.Criticality == tasks.LC {
+			job.AbsoluteDeadline = job.ReleaseTime + s.elasticDeadline(job.Task)
+			job.VirtualDeadline = job.AbsoluteDeadline
+			s.pushEvent(&event{time: job.AbsoluteDeadline, kind: deadlineEvent, job: job})
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"container/heap"
+
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// eventKind identifies what happens at an event instant. Events sharing the
+// same instant are handled in the order of their kinds, so that a job finishing
+// exactly at its deadline is not reported as a miss, and a job released at the
+// same instant as a completion sees the processor already free.
+type eventKind int
+
+const (
+	completionEvent eventKind = iota
+	csBoundaryEvent
+	budgetEvent
+	modeReturnEvent
+	releaseEvent
+	deadlineEvent
+)
+
+// event is a point in time at which the scheduling decision may change.
+type event struct {
+	time float64
+	kind eventKind
+	task *tasks.Task
+	job  *Job
+
+	// dispatch is the dispatch stamp of the running job at the time the event
+	// was created. Events of the running job (completion, CS boundary, budget
+	// exhaustion) become stale once the job is preempted or finished.
+	dispatch int
+
+	// epoch is the mode epoch of a mode return event. The event is stale if the
+	// mode changed again in the meantime.
+	epoch int
+
+	// point is the execution time of the job at which a running job event fires.
+	point float64
+
+	// order breaks ties between events with the same time and kind so that the
+	// simulation is deterministic.
+	order int
+}
+
+// eventQueue is a min-heap of events ordered by time, kind and insertion order.
+type eventQueue []*event
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].time != q[j].time {
+		return q[i].time < q[j].time
+	}
+	if q[i].kind != q[j].kind {
+		return q[i].kind < q[j].kind
+	}
+	return q[i].order < q[j].order
+}
+
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x any) { *q = append(*q, x.(*event)) }
+
+func (q *eventQueue) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return e
+}
+
+// push adds an event to the queue.
+func (q *eventQueue) push(e *event) {
+	heap.Push(q, e)
+}
+
+// pop removes and returns the earliest event.
+func (q *eventQueue) pop() *event {
+	return heap.Pop(q).(*event)
+}
+
+// peek returns the earliest event without removing it.
+func (q eventQueue) peek() *event {
+	if len(q) == 0 {
+		return nil
+	}
+	return q[0]
+}
@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// Execution models selectable with the kind field of the exec_model config section.
+const (
+	WCETExecution    = "wcet"
+	UniformExecution = "uniform"
+	BetaExecution    = "beta"
+	TableExecution   = "table"
+)
+
+// ExecutionModel draws the actual execution time (demand) of jobs. The demand is
+// independent of the budget the scheduler grants the job: an HC job whose demand
+// exceeds WCET1 triggers the switch to Overrun mode. The simulator caps the
+// demand at WCET1 for LC jobs and at WCET1+WCET2 for HC jobs.
+type ExecutionModel interface {
+	// ExecutionTime returns the demand of the index-th job (starting at 1) of the task.
+	ExecutionTime(t *tasks.Task, index int) float64
+}
+
+// NewExecutionModel builds the execution model described by the configuration.
+// By default every job executes exactly its WCET1. Random draws come from rng.
+func NewExecutionModel(cfg config.ExecModelConfig, rng *rand.Rand) ExecutionModel {
+	var fraction func() float64
+	switch cfg.Kind {
+	case UniformExecution:
+		fraction = func() float64 {
+			return cfg.Range[0] + rng.Float64()*(cfg.Range[1]-cfg.Range[0])
+		}
+	case BetaExecution:
+		fraction = func() float64 {
+			return betaVariate(rng, cfg.Alpha, cfg.Beta)
+		}
+	default:
+		fraction = func() float64 { return 1 }
+	}
+
+	var model ExecutionModel = &fractionModel{
+		rng:                    rng,
+		fraction:               fraction,
+		overrunProbability:     cfg.OverrunProbability,
+		taskOverrunProbability: cfg.TaskOverrunProbability,
+	}
+	if cfg.Kind == TableExecution {
+		model = &tableModel{table: cfg.Table, fallback: model}
+	}
+	return model
+}
+
+// fractionModel executes a random fraction of the WCET1 of the job. HC jobs
+// overrun with the configured probability, executing their WCET1 plus a random
+// fraction of their WCET2.
+type fractionModel struct {
+	rng                    *rand.Rand
+	fraction               func() float64
+	overrunProbability     float64
+	taskOverrunProbability map[int]float64
+}
+
+func (m *fractionModel) ExecutionTime(t *tasks.Task, index int) float64 {
+	f := m.fraction()
+	if t.Criticality == tasks.HC {
+		p, ok := m.taskOverrunProbability[t.ID]
+		if !ok {
+			p = m.overrunProbability
+		}
+		if m.rng.Float64() < p {
+			return t.WCET1 + f*t.WCET2
+		}
+	}
+	return f * t.WCET1
+}
+
+// tableModel takes the demand of each job from an explicit per-task table and
+// falls back to another model for the jobs the table does not list.
+type tableModel struct {
+	table    map[int][]float64
+	fallback ExecutionModel
+}
+
+func (m *tableModel) ExecutionTime(t *tasks.Task, index int) float64 {
+	if times := m.table[t.ID]; index <= len(times) {
+		return times[index-1]
+	}
+	return m.fallback.ExecutionTime(t, index)
+}
+
+// betaVariate draws a Beta(alpha, beta) distributed value from two gamma variates.
+func betaVariate(rng *rand.Rand, alpha, beta float64) float64 {
+	x := gammaVariate(rng, alpha)
+	y := gammaVariate(rng, beta)
+	if x+y == 0 {
+		return 0
+	}
+	return x / (x + y)
+}
+
+// gammaVariate draws a Gamma(shape, 1) distributed value using the method of
+// Marsaglia and Tsang.
+func gammaVariate(rng *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		// Boost the shape and correct with a uniform power.
+		return gammaVariate(rng, shape+1) * math.Pow(rng.Float64(), 1/shape)
+	}
+
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
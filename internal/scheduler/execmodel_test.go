@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+func TestExecutionModel(t *testing.T) {
+	lc := &tasks.Task{ID: 1, Criticality: tasks.LC, WCET1: 10}
+	hc := &tasks.Task{ID: 2, Criticality: tasks.HC, WCET1: 10, WCET2: 5}
+
+	tests := []struct {
+		name string
+		cfg  config.ExecModelConfig
+		task *tasks.Task
+
+		// Every demand lies in [min, max] and the demands average mean.
+		min, max, mean float64
+	}{
+		{name: "wcet", task: hc, min: 10, max: 10, mean: 10},
+		{
+			name: "uniform",
+			cfg:  config.ExecModelConfig{Kind: UniformExecution, Range: [2]float64{0.6, 1}},
+			task: lc, min: 6, max: 10, mean: 8,
+		},
+		{
+			name: "beta",
+			cfg:  config.ExecModelConfig{Kind: BetaExecution, Alpha: 2, Beta: 6},
+			task: lc, min: 0, max: 10, mean: 2.5,
+		},
+		{
+			// HC jobs always overrun, executing WCET1 plus a fraction of WCET2.
+			name: "overrun",
+			cfg:  config.ExecModelConfig{Kind: UniformExecution, Range: [2]float64{0, 1}, OverrunProbability: 1},
+			task: hc, min: 10, max: 15, mean: 12.5,
+		},
+		{
+			// LC jobs never overrun.
+			name: "overrun of LC jobs",
+			cfg:  config.ExecModelConfig{OverrunProbability: 1},
+			task: lc, min: 10, max: 10, mean: 10,
+		},
+		{
+			name: "task overrun probability",
+			cfg:  config.ExecModelConfig{OverrunProbability: 1, TaskOverrunProbability: map[int]float64{2: 0}},
+			task: hc, min: 10, max: 10, mean: 10,
+		},
+	}
+
+	const draws = 20000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewExecutionModel(tt.cfg, rand.New(rand.NewSource(1)))
+			sum := 0.0
+			for i := 1; i <= draws; i++ {
+				demand := model.ExecutionTime(tt.task, i)
+				if demand < tt.min || demand > tt.max {
+					t.Fatalf("demand %g of job %d outside [%g, %g]", demand, i, tt.min, tt.max)
+				}
+				sum += demand
+			}
+			if mean := sum / draws; math.Abs(mean-tt.mean) > 0.05 {
+				t.Errorf("mean demand = %g, want %g", mean, tt.mean)
+			}
+		})
+	}
+}
+
+func TestTableExecutionModel(t *testing.T) {
+	lc := &tasks.Task{ID: 1, Criticality: tasks.LC, WCET1: 10}
+	cfg := config.ExecModelConfig{Kind: TableExecution, Table: map[int][]float64{1: {3, 12}}}
+	model := NewExecutionModel(cfg, rand.New(rand.NewSource(1)))
+
+	// The jobs the table does not list execute their WCET1. The simulator, not
+	// the model, caps the demand of the second job.
+	for index, want := range map[int]float64{1: 3, 2: 12, 3: 10} {
+		if got := model.ExecutionTime(lc, index); got != want {
+			t.Errorf("demand of job %d = %g, want %g", index, got, want)
+		}
+	}
+}
+
+func TestExecutionModelSeed(t *testing.T) {
+	cfg := config.ExecModelConfig{Kind: BetaExecution, Alpha: 0.5, Beta: 0.5, OverrunProbability: 0.3}
+	hc := &tasks.Task{ID: 2, Criticality: tasks.HC, WCET1: 10, WCET2: 5}
+	a := NewExecutionModel(cfg, rand.New(rand.NewSource(42)))
+	b := NewExecutionModel(cfg, rand.New(rand.NewSource(42)))
+	for i := 1; i <= 100; i++ {
+		if x, y := a.ExecutionTime(hc, i), b.ExecutionTime(hc, i); x != y {
+			t.Fatalf("job %d: demands %g and %g drawn from the same seed", i, x, y)
+		}
+	}
+}
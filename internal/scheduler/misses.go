@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"github.com/99109766/fms-scheduler/internal/tasks"
+	"github.com/99109766/fms-scheduler/internal/trace"
+)
+
+// Policies applied to a job missing its deadline. The simulation goes on in
+// every case.
+const (
+	// MissAbort aborts the late job, releasing its resources (the default).
+	MissAbort = "abort"
+	// MissContinue lets the late job run to completion.
+	MissContinue = "continue"
+	// MissSkip lets the late job run to completion and skips the next release of
+	// its task to catch up.
+	MissSkip = "skip"
+)
+
+// Outcomes of a job that missed its deadline.
+const (
+	MissCompletedLate = "completed-late"
+	MissAborted       = "aborted"
+	MissUnfinished    = "unfinished"
+)
+
+// Miss describes a deadline miss. Lateness is the time between the deadline and
+// the completion of the job. For jobs that never complete, it is the time up to
+// the abort or the end of the simulation, a lower bound of the real lateness.
+type Miss struct {
+	JobID       int                    `json:"job_id"`
+	TaskID      int                    `json:"task_id"`
+	Criticality tasks.CriticalityLevel `json:"criticality"`
+	Mode        Mode                   `json:"mode"`
+	Deadline    float64                `json:"deadline"`
+	Lateness    float64                `json:"lateness"`
+	Outcome     string                 `json:"outcome"`
+}
+
+// missDeadline records the deadline miss of the job and applies the miss policy.
+func (s *simulator) missDeadline(job *Job) {
+	job.missed = true
+	e := jobEvent(trace.Miss, job)
+	e.Policy = s.missPolicy
+	s.emit(e)
+	s.misses = append(s.misses, Miss{
+		JobID:       job.JobID,
+		TaskID:      job.Task.ID,
+		Criticality: job.Task.Criticality,
+		Mode:        s.mode,
+		Deadline:    job.AbsoluteDeadline,
+		Outcome:     MissUnfinished,
+	})
+	s.noteInjectedMiss(job)
+
+	switch s.missPolicy {
+	case MissContinue:
+		s.late[job] = len(s.misses) - 1
+	case MissSkip:
+		s.late[job] = len(s.misses) - 1
+		s.skipNext[job.Task.ID] = true
+	default:
+		s.misses[len(s.misses)-1].Outcome = MissAborted
+		s.abort(job)
+	}
+}
+
+// abort removes the job from the system and releases its resources.
+func (s *simulator) abort(job *Job) {
+	if s.runningJob == job {
+		s.runningJob = nil
+	}
+	s.readyQueue = removeJob(s.readyQueue, job)
+	s.blockedJobs = removeJob(s.blockedJobs, job)
+	job.done, job.outcome, job.finishTime = true, OutcomeAborted, s.currentTime
+	s.emit(jobEvent(trace.Abort, job))
+	s.finishInjected(job, OutcomeAborted)
+	s.releaseResources(job, nil)
+}
+
+// completeLate records the lateness of a job completing after its deadline.
+func (s *simulator) completeLate(job *Job) {
+	if i, ok := s.late[job]; ok {
+		s.misses[i].Lateness = s.currentTime - s.misses[i].Deadline
+		s.misses[i].Outcome = MissCompletedLate
+		delete(s.late, job)
+	}
+}
+
+// missReports returns all deadline misses, bounding the lateness of the late
+// jobs still pending at the end of the simulation.
+func (s *simulator) missReports() []Miss {
+	for _, i := range s.late {
+		s.misses[i].Lateness = s.currentTime - s.misses[i].Deadline
+	}
+	return s.misses
+}
+
+// removeJob removes the job from the queue, if present.
+func removeJob(queue []*Job, job *Job) []*Job {
+	for i, other := range queue {
+		if other == job {
+			return append(queue[:i], queue[i+1:]...)
+		}
+	}
+	return queue
+}
@@ -4,42 +4,200 @@ import "github.com/99109766/fms-scheduler/internal/tasks"
 
 type Schedule struct {
 	TaskID    int     `json:"task_id"`
+	JobID     int     `json:"job_id"`
 	StartTime float64 `json:"start_time"`
 	EndTime   float64 `json:"end_time"`
 }
 
+// Hold is an interval during which a job holds units of a resource.
+type Hold struct {
+	JobID      int     `json:"job_id"`
+	TaskID     int     `json:"task_id"`
+	ResourceID int     `json:"resource_id"`
+	Units      int     `json:"units"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+}
+
+// Result is the outcome of a simulation run.
+type Result struct {
+	// Algorithm and Protocol are the scheduling algorithm and the resource access
+	// protocol of the run.
+	Algorithm string `json:"algorithm"`
+	Protocol  string `json:"protocol"`
+
+	// EarlyRelease reports whether LC tasks kept being released in Overrun mode
+	// under ER-EDF. Otherwise no LC job runs in Overrun mode.
+	EarlyRelease bool `json:"early_release,omitempty"`
+
+	// Seed is the seed of the random execution times of the jobs, and TaskSetSeed
+	// the seed the task set was generated with, if it was generated.
+	Seed         int64        `json:"seed"`
+	TaskSetSeed  int64        `json:"task_set_seed,omitempty"`
+	SimulateTime float64      `json:"simulate_time"`
+	Schedule     []Schedule   `json:"schedule"`
+	Holds        []Hold       `json:"holds"`
+	ModeSwitches []ModeSwitch `json:"mode_switches"`
+	Misses       []Miss       `json:"misses"`
+	Jobs         []JobRecord  `json:"jobs"`
+
+	// Deadlocks lists the deadlocks found among blocked jobs, which only the
+	// protocols not preventing them let happen.
+	Deadlocks []Deadlock `json:"deadlocks,omitempty"`
+
+	// Suppressed lists the releases of LC tasks suppressed in Overrun mode, and
+	// Skipped the releases skipped by the skip miss policy.
+	Suppressed []Suppression `json:"suppressed,omitempty"`
+	Skipped    []Suppression `json:"skipped,omitempty"`
+
+	// Shifted lists the releases later than one period after the previous
+	// release of their task.
+	Shifted []ShiftedRelease `json:"shifted,omitempty"`
+
+	// Injections reports the response of the system to the events injected by
+	// a scenario.
+	Injections []*InjectionReport `json:"injections,omitempty"`
+}
+
+// Suppression is a release of a task at which no job is released: an LC task
+// in Overrun mode, or a task whose release is skipped to catch up. Mode is the
+// mode of the system at the release.
+type Suppression struct {
+	Time   float64 `json:"time"`
+	TaskID int     `json:"task_id"`
+	Mode   Mode    `json:"mode"`
+}
+
+// ShiftedRelease is a release of a task later than its Nominal release on its
+// period grid: a delayed release, an early release of an LC task from the
+// reclaimed slack, or a release at its extended period. The following releases
+// of the task are periodic from it.
+type ShiftedRelease struct {
+	Time    float64 `json:"time"`
+	Nominal float64 `json:"nominal"`
+	TaskID  int     `json:"task_id"`
+}
+
+// Outcomes of a job. OutcomeNotReleased only describes injections whose target
+// job was never released.
+const (
+	OutcomeCompleted   = "completed"
+	OutcomeAborted     = "aborted"
+	OutcomeDropped     = "dropped"
+	OutcomeUnfinished  = "unfinished"
+	OutcomeNotReleased = "not-released"
+)
+
+// JobRecord summarises the execution of a job. StartTime is only meaningful if
+// the job started. FinishTime is the time the job completed, or was dropped or
+// aborted. BlockingTime is the time the pending job spent waiting while a job
+// with a lower priority ran.
+type JobRecord struct {
+	JobID        int                    `json:"job_id"`
+	TaskID       int                    `json:"task_id"`
+	Criticality  tasks.CriticalityLevel `json:"criticality"`
+	ReleaseMode  Mode                   `json:"release_mode"`
+	ReleaseTime  float64                `json:"release_time"`
+	Deadline     float64                `json:"deadline"`
+	Demand       float64                `json:"demand"`
+	Started      bool                   `json:"started"`
+	StartTime    float64                `json:"start_time"`
+	FinishTime   float64                `json:"finish_time"`
+	ExecTime     float64                `json:"exec_time"`
+	Preemptions  int                    `json:"preemptions"`
+	BlockingTime float64                `json:"blocking_time"`
+	Missed       bool                   `json:"missed"`
+	Outcome      string                 `json:"outcome"`
+}
+
 type Job struct {
 	Task             *tasks.Task
 	JobID            int
+	Index            int
 	ReleaseTime      float64
 	AbsoluteDeadline float64
+	VirtualDeadline  float64
 	RemainingTime    float64
+	Budget           float64
+	Demand           float64
 	ExecTime         float64
+
+	done           bool
+	started        bool
+	ceilingBlocked bool
+	sections       []*tasks.CriticalSection
+	held           map[int]int
+
+	// waitFor is the resource whose holders block the job, or 0. deadlocked
+	// records that the job was found in a deadlock.
+	waitFor    int
+	deadlocked bool
+
+	// holds maps the resources held by the job to their open hold interval.
+	holds map[int]int
+
+	// Execution statistics of the job.
+	releaseMode Mode
+	startTime   float64
+	finishTime  float64
+	preemptions int
+	blocking    float64
+	missed      bool
+	outcome     string
 }
 
-// getActiveCriticalSection returns the active critical section for the job,
-// if any. (The one with the shortest duration in case of multiple overlapping CSs.)
-func (job *Job) getActiveCriticalSection() *tasks.CriticalSection {
-	var bestCS *tasks.CriticalSection
-	for _, cs := range job.Task.CriticalSections {
-		// Check if job execution is within the CS interval.
-		if job.ExecTime >= cs.Start && job.ExecTime < cs.Start+cs.Duration {
-			// If the current CS is shorter than the best one, update the best.
-			if bestCS == nil || cs.Duration < bestCS.Duration {
-				bestCS = cs
+// record returns the execution record of the job.
+func (job *Job) record() JobRecord {
+	return JobRecord{
+		JobID:        job.JobID,
+		TaskID:       job.Task.ID,
+		Criticality:  job.Task.Criticality,
+		ReleaseMode:  job.releaseMode,
+		ReleaseTime:  job.ReleaseTime,
+		Deadline:     job.AbsoluteDeadline,
+		Demand:       job.Demand,
+		Started:      job.started,
+		StartTime:    job.startTime,
+		FinishTime:   job.finishTime,
+		ExecTime:     job.ExecTime,
+		Preemptions:  job.preemptions,
+		BlockingTime: job.blocking,
+		Missed:       job.missed,
+		Outcome:      job.outcome,
+	}
+}
+
+// nextCSBoundary returns the next critical section entry or exit point (in
+// terms of the job's execution time) strictly after its current execution time.
+func (job *Job) nextCSBoundary() (float64, bool) {
+	next, found := 0.0, false
+	for _, cs := range job.sections {
+		for _, point := range []float64{cs.Start, cs.End()} {
+			if point > job.ExecTime+epsilon && (!found || point < next) {
+				next, found = point, true
 			}
 		}
 	}
-	return bestCS
+	return next, found
 }
 
-// effectivePriority returns a numeric “priority” for the job.
-// For jobs not in a critical section we use the absolute deadline (lower is better).
-// When inside a critical section the job’s effective priority is its preemption level.
-func (job *Job) effectivePriority() float64 {
-	if job.getActiveCriticalSection() != nil {
-		// When in a critical section, the job’s effective priority is its preemption level.
-		return float64(job.Task.PreemptionLevel)
+// demandedUnits returns the number of units of each resource the job holds at
+// its current execution point, and the resources in acquisition order: a
+// critical section before the ones nested in it. Nested critical sections on
+// the same resource re-acquire the resource, so the larger request counts.
+func (job *Job) demandedUnits() (map[int]int, []int) {
+	demand := make(map[int]int)
+	var order []int
+	for _, cs := range job.sections {
+		// Check if job execution is within the CS interval.
+		if cs.Start <= job.ExecTime+epsilon && job.ExecTime+epsilon < cs.End() {
+			if _, ok := demand[cs.ResourceID]; !ok {
+				order = append(order, cs.ResourceID)
+			}
+			if cs.Units > demand[cs.ResourceID] {
+				demand[cs.ResourceID] = cs.Units
+			}
+		}
 	}
-	return job.AbsoluteDeadline
+	return demand, order
 }
@@ -0,0 +1,208 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/99109766/fms-scheduler/internal/tasks"
+	"github.com/99109766/fms-scheduler/internal/trace"
+)
+
+// Mode indicates the current system mode.
+type Mode int
+
+const (
+	Normal Mode = iota
+	Overrun
+)
+
+func (m Mode) String() string {
+	if m == Overrun {
+		return "Overrun"
+	}
+	return "Normal"
+}
+
+func (m Mode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText accepts "Normal" and "Overrun", and the numeric form of files
+// written before modes were named: 0 for Normal and 1 for Overrun.
+func (m *Mode) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "Normal", "0":
+		*m = Normal
+	case "Overrun", "1":
+		*m = Overrun
+	default:
+		return fmt.Errorf("unknown mode %q", text)
+	}
+	return nil
+}
+
+// UnmarshalJSON accepts the mode as a string or as a legacy number. Null leaves
+// the mode unchanged.
+func (m *Mode) UnmarshalJSON(data []byte) error {
+	return tasks.UnmarshalTextOrNumber(data, m.UnmarshalText)
+}
+
+// Mode return policies selectable with the mode_return config field.
+const (
+	ReturnNever       = "never"
+	ReturnIdle        = "idle"
+	ReturnHyperperiod = "hyperperiod"
+	ReturnDwell       = "dwell"
+)
+
+// ModeSwitch records a transition between system modes.
+type ModeSwitch struct {
+	Time   float64 `json:"time"`
+	From   Mode    `json:"from"`
+	To     Mode    `json:"to"`
+	Reason string  `json:"reason"`
+	TaskID int     `json:"task_id,omitempty"`
+	JobID  int     `json:"job_id,omitempty"`
+}
+
+// switchToOverrun switches the system to Overrun mode because the job exceeded
+// its WCET1 budget, and schedules the return to Normal mode if the policy is time based.
+func (s *simulator) switchToOverrun(job *Job) {
+	s.mode = Overrun
+	s.modeSwitches = append(s.modeSwitches, ModeSwitch{
+		Time: s.currentTime, From: Normal, To: Overrun, Reason: "overrun",
+		TaskID: job.Task.ID, JobID: job.JobID,
+	})
+	e := jobEvent(trace.ModeSwitch, job)
+	e.From, e.To, e.Reason = Normal.String(), Overrun.String(), "overrun"
+	s.emit(e)
+
+	// Drop pending LC jobs, or let them continue against elastic deadlines.
+	var droppedReady, droppedBlocked []*Job
+	if s.earlyRelease {
+		s.slack = 0
+		s.extendLCDeadlines(s.readyQueue)
+		s.extendLCDeadlines(s.blockedJobs)
+	} else {
+		s.readyQueue, droppedReady = s.dropLCJobs(s.readyQueue)
+		s.blockedJobs, droppedBlocked = s.dropLCJobs(s.blockedJobs)
+	}
+
+	// Extend the budgets of the HC jobs to include WCET2.
+	extendBudgets(s.readyQueue)
+	extendBudgets(s.blockedJobs)
+	extendBudgets([]*Job{job})
+
+	// Let the policy adjust the pending jobs, e.g. restore the real deadlines of
+	// the HC jobs shrunk by EDF-VD.
+	for _, queue := range [][]*Job{s.readyQueue, s.blockedJobs, {job}} {
+		for _, pending := range queue {
+			s.policy.SwitchToOverrun(pending)
+		}
+	}
+
+	dropped := append(droppedReady, droppedBlocked...)
+	for _, job := range dropped {
+		s.finishInjected(job, OutcomeDropped)
+		s.releaseResources(job, nil)
+	}
+	s.noteInjectedSwitch(job, len(dropped))
+
+	if s.modeReturn == ReturnHyperperiod || s.modeReturn == ReturnDwell {
+		s.modeEpoch++
+		s.pushEvent(&event{time: s.currentTime + s.modeDwell, kind: modeReturnEvent, epoch: s.modeEpoch})
+	}
+}
+
+// requestReturn returns to Normal mode, unless a pending HC job already executed
+// past its WCET1 budget. Back under WCET1 budget enforcement, such a job would
+// switch the system straight back to Overrun mode, so the return is deferred
+// until no pending job is in its WCET2 extension.
+func (s *simulator) requestReturn(reason string) {
+	if s.pendingExtension() {
+		if s.deferredReturn == "" {
+			s.emit(trace.Event{Kind: trace.Note, Message: "Return to NORMAL mode deferred until the HC jobs past their WCET1 budget finish"})
+		}
+		s.deferredReturn = reason
+		return
+	}
+	s.returnToNormal(reason)
+}
+
+// checkDeferredReturn returns to Normal mode once the return deferred by
+// requestReturn is possible.
+func (s *simulator) checkDeferredReturn() {
+	if s.mode == Overrun && s.deferredReturn != "" && !s.pendingExtension() {
+		s.returnToNormal(s.deferredReturn)
+	}
+}
+
+// pendingExtension reports whether a pending HC job executed past its WCET1 budget.
+func (s *simulator) pendingExtension() bool {
+	pending := append([]*Job{s.runningJob}, s.readyQueue...)
+	for _, job := range append(pending, s.blockedJobs...) {
+		if job != nil && !job.done && job.Task.Criticality == tasks.HC && job.ExecTime >= job.Task.WCET1-epsilon {
+			return true
+		}
+	}
+	return false
+}
+
+// returnToNormal switches the system back to Normal mode. LC tasks are released
+// again at their nominal periods.
+func (s *simulator) returnToNormal(reason string) {
+	s.mode = Normal
+	s.modeEpoch++
+	s.deferredReturn = ""
+	s.modeSwitches = append(s.modeSwitches, ModeSwitch{
+		Time: s.currentTime, From: Overrun, To: Normal, Reason: reason,
+	})
+	s.emit(trace.Event{Kind: trace.ModeSwitch, From: Overrun.String(), To: Normal.String(), Reason: reason})
+
+	// LC tasks waiting for slack to be released early are released right away.
+	for _, t := range s.taskSet {
+		if s.pendingEarly[t.ID] {
+			s.release(t, s.currentTime)
+		}
+	}
+
+	// The running job is back under WCET1 budget enforcement.
+	if s.runningJob != nil {
+		s.dispatch++
+		s.scheduleRunningJobEvents()
+	}
+}
+
+// checkIdleReturn returns to Normal mode at an idle instant if the idle policy is selected.
+func (s *simulator) checkIdleReturn() {
+	if s.mode == Overrun && s.modeReturn == ReturnIdle &&
+		s.runningJob == nil && len(s.readyQueue) == 0 && len(s.blockedJobs) == 0 {
+		s.returnToNormal(ReturnIdle)
+	}
+}
+
+// dropLCJobs removes all low-criticality jobs from the queue and returns them
+// separately, so that the caller can release the resource units they hold.
+// This is used when the system switches to Overrun mode.
+func (s *simulator) dropLCJobs(queue []*Job) ([]*Job, []*Job) {
+	newQueue, dropped := []*Job{}, []*Job{}
+	for _, job := range queue {
+		if job.Task.Criticality == tasks.HC {
+			newQueue = append(newQueue, job)
+		} else {
+			job.done, job.outcome, job.finishTime = true, OutcomeDropped, s.currentTime
+			dropped = append(dropped, job)
+			s.emit(jobEvent(trace.Drop, job))
+		}
+	}
+	return newQueue, dropped
+}
+
+// extendBudgets extends the budgets of all HC jobs in the queue to WCET1+WCET2.
+// This is used when the system switches to Overrun mode.
+func extendBudgets(jobs []*Job) {
+	for _, job := range jobs {
+		if job.Task.Criticality == tasks.HC {
+			job.Budget = job.Task.WCET1 + job.Task.WCET2
+		}
+	}
+}
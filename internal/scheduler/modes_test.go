@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestModeJSON(t *testing.T) {
+	tests := []struct {
+		data  string
+		mode  Mode
+		valid bool
+	}{
+		{data: `"Normal"`, mode: Normal, valid: true},
+		{data: `"Overrun"`, mode: Overrun, valid: true},
+		{data: `0`, mode: Normal, valid: true},
+		{data: `1`, mode: Overrun, valid: true},
+		{data: `2`},
+		{data: `"NORMAL"`},
+		{data: `null`, mode: Normal, valid: true},
+	}
+
+	for _, tt := range tests {
+		var mode Mode
+		err := json.Unmarshal([]byte(tt.data), &mode)
+		if (err == nil) != tt.valid {
+			t.Errorf("Unmarshal(%s) error = %v, want valid %v", tt.data, err, tt.valid)
+			continue
+		}
+		if tt.valid && mode != tt.mode {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.data, mode, tt.mode)
+		}
+	}
+
+	var miss Miss
+	if err := json.Unmarshal([]byte(`{"criticality": 1, "mode": 1}`), &miss); err != nil || miss.Mode != Overrun {
+		t.Errorf("legacy miss decoded as %+v, %v", miss, err)
+	}
+}
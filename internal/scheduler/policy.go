@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// Policy orders the jobs competing for the processor. The simulator asks it for
+// the scheduling deadline of every released job, the priority of pending jobs,
+// whether a ready job preempts the running job, and how pending jobs react to
+// the switch to Overrun mode.
+type Policy interface {
+	// Setup prepares the policy for the task set before the simulation starts.
+	// It returns a note to trace, or "".
+	Setup(taskSet []*tasks.Task) string
+
+	// Release sets the scheduling (virtual) deadline of a job released in the mode.
+	Release(job *Job, mode Mode)
+
+	// Priority returns the priority of the job. Lower values run first.
+	Priority(job *Job) float64
+
+	// Preempts reports whether the ready job preempts the running job.
+	Preempts(ready, running *Job) bool
+
+	// SwitchToOverrun adjusts a pending job when the system switches to Overrun mode.
+	SwitchToOverrun(job *Job)
+
+	// SuspendsLC reports whether LC tasks are suspended in Overrun mode even if
+	// early release is enabled.
+	SuspendsLC() bool
+}
+
+// NewPolicy returns the scheduling policy selected by the algorithm config
+// field. Plain EDF is the default.
+func NewPolicy(algorithm string) (Policy, error) {
+	switch algorithm {
+	case "", tasks.EDF:
+		return &edfPolicy{factor: 1}, nil
+	case tasks.EDFVD:
+		return &edfPolicy{factor: 1, virtual: true}, nil
+	case tasks.DM:
+		return &fixedPriorityPolicy{deadlineMonotonic: true}, nil
+	case tasks.RM:
+		return &fixedPriorityPolicy{}, nil
+	case tasks.AMC:
+		return &fixedPriorityPolicy{amc: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown scheduling algorithm %q", algorithm)
+	}
+}
+
+// edfPolicy runs the job with the earliest deadline. Under EDF-VD, HC jobs
+// released in Normal mode are scheduled by deadlines shrunk by the factor x
+// until the system switches to Overrun mode.
+type edfPolicy struct {
+	virtual bool
+	factor  float64
+}
+
+func (p *edfPolicy) Setup(taskSet []*tasks.Task) string {
+	if !p.virtual {
+		return ""
+	}
+	x, ok := tasks.VirtualDeadlineFactor(taskSet)
+	p.factor = x
+	return fmt.Sprintf("EDF-VD scaling factor x=%.3f [Schedulable=%v]", x, ok)
+}
+
+func (p *edfPolicy) Release(job *Job, mode Mode) {
+	job.VirtualDeadline = job.AbsoluteDeadline
+	if p.virtual && job.Task.Criticality == tasks.HC && mode == Normal {
+		job.VirtualDeadline = job.ReleaseTime + p.factor*job.Task.Deadline
+	}
+}
+
+func (p *edfPolicy) Priority(job *Job) float64 {
+	return job.VirtualDeadline
+}
+
+func (p *edfPolicy) Preempts(ready, running *Job) bool {
+	return p.Priority(ready) < p.Priority(running)
+}
+
+// SwitchToOverrun restores the real deadlines of the HC jobs shrunk by EDF-VD.
+func (p *edfPolicy) SwitchToOverrun(job *Job) {
+	job.VirtualDeadline = job.AbsoluteDeadline
+}
+
+func (p *edfPolicy) SuspendsLC() bool {
+	return false
+}
+
+// fixedPriorityPolicy runs the job whose task has the highest static priority:
+// the shortest relative deadline (deadline-monotonic) or the shortest period
+// (rate-monotonic). Under fixed-priority AMC, LC tasks are suspended in Overrun
+// mode, as in the AMC model.
+type fixedPriorityPolicy struct {
+	deadlineMonotonic bool
+	amc               bool
+
+	// ranks maps the task IDs to their rate-monotonic priorities, 1 for the
+	// shortest period.
+	ranks map[int]int
+}
+
+// Setup ranks the tasks by period if the policy uses rate-monotonic priorities.
+// The task set and its tasks are left untouched.
+func (p *fixedPriorityPolicy) Setup(taskSet []*tasks.Task) string {
+	if p.deadlineMonotonic {
+		return ""
+	}
+	ranked := make([]*tasks.Task, len(taskSet))
+	copy(ranked, taskSet)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Period < ranked[j].Period
+	})
+	p.ranks = make(map[int]int, len(ranked))
+	for rank, t := range ranked {
+		p.ranks[t.ID] = rank + 1
+	}
+	return ""
+}
+
+func (p *fixedPriorityPolicy) Release(job *Job, mode Mode) {
+	job.VirtualDeadline = job.AbsoluteDeadline
+}
+
+func (p *fixedPriorityPolicy) Priority(job *Job) float64 {
+	if p.deadlineMonotonic {
+		return job.Task.Deadline
+	}
+	return float64(p.ranks[job.Task.ID])
+}
+
+func (p *fixedPriorityPolicy) Preempts(ready, running *Job) bool {
+	return p.Priority(ready) < p.Priority(running)
+}
+
+func (p *fixedPriorityPolicy) SwitchToOverrun(job *Job) {}
+
+func (p *fixedPriorityPolicy) SuspendsLC() bool {
+	return p.amc
+}
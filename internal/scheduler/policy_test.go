@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+func TestFixedPriorityPolicy(t *testing.T) {
+	taskSet := []*tasks.Task{
+		{ID: 1, Period: 20, Deadline: 5, Priority: 7},
+		{ID: 2, Period: 10, Deadline: 10, Priority: 8},
+		{ID: 3, Period: 10, Deadline: 8, Priority: 9},
+	}
+	tests := []struct {
+		algorithm  string
+		priorities map[int]float64
+	}{
+		// Equal periods keep the order of the task set.
+		{algorithm: tasks.RM, priorities: map[int]float64{1: 3, 2: 1, 3: 2}},
+		{algorithm: tasks.AMC, priorities: map[int]float64{1: 3, 2: 1, 3: 2}},
+		{algorithm: tasks.DM, priorities: map[int]float64{1: 5, 2: 10, 3: 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			policy, err := NewPolicy(tt.algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			policy.Setup(taskSet)
+			for _, task := range taskSet {
+				if got := policy.Priority(&Job{Task: task}); got != tt.priorities[task.ID] {
+					t.Errorf("priority of task %d = %g, want %g", task.ID, got, tt.priorities[task.ID])
+				}
+			}
+			for i, task := range taskSet {
+				if task.ID != i+1 || task.Priority != i+7 {
+					t.Errorf("Setup modified the task set: task %d at %d with priority %d", task.ID, i, task.Priority)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package scheduler
+
+import "fmt"
+
+// Resource access protocols selectable with the protocol config field.
+const (
+	ProtocolNone = "none"
+	ProtocolNPCS = "npcs"
+	ProtocolPIP  = "pip"
+	ProtocolPCP  = "pcp"
+	ProtocolSRP  = "srp"
+)
+
+// ResourceState is the view of the shared resources a protocol decides on.
+type ResourceState interface {
+	// Available returns the number of free units of the resource.
+	Available(resourceID int) int
+
+	// Ceiling returns the current ceiling of the resources as if the job, which
+	// may be nil, held no units: the highest (i.e. numerically lowest) ceiling of
+	// any resource given its available units, and that resource. math.MaxInt32
+	// means no resource is held.
+	Ceiling(exclude *Job) (ceiling, resourceID int)
+}
+
+// Protocol arbitrates the access of jobs to the shared resources. The simulator
+// asks it whether a job may start, whether a job entering a critical section may
+// lock the units it requests, whether the running job may be preempted, and
+// whether jobs holding resources inherit the priority of the jobs they block.
+// Units are unlocked when a critical section is left, which wakes up the jobs
+// blocked on resources.
+type Protocol interface {
+	// Admits reports whether the job may start executing.
+	Admits(job *Job, state ResourceState) bool
+
+	// Lock reports whether the job may lock the extra units of the resource. If
+	// not, it also returns the resource whose holders block the job.
+	Lock(job *Job, resourceID, units int, state ResourceState) (bool, int)
+
+	// Preemptible reports whether the running job may be preempted.
+	Preemptible(job *Job) bool
+
+	// Inherits reports whether jobs holding resources inherit the priority of
+	// the jobs they block.
+	Inherits() bool
+
+	// PreventsDeadlock reports whether the protocol rules out deadlocks on a
+	// single processor, whatever order critical sections are nested in.
+	PreventsDeadlock() bool
+}
+
+// Protocols lists the resource access protocols.
+var Protocols = []string{ProtocolNone, ProtocolNPCS, ProtocolPIP, ProtocolPCP, ProtocolSRP}
+
+// NewProtocol returns the resource access protocol selected by the protocol
+// config field. The multi-unit Stack Resource Policy is the default.
+func NewProtocol(name string) (Protocol, error) {
+	switch name {
+	case "", ProtocolSRP:
+		return srpProtocol{}, nil
+	case ProtocolNone:
+		return lockProtocol{}, nil
+	case ProtocolNPCS:
+		return lockProtocol{nonPreemptive: true}, nil
+	case ProtocolPIP:
+		return lockProtocol{inherit: true}, nil
+	case ProtocolPCP:
+		return pcpProtocol{}, nil
+	default:
+		return nil, fmt.Errorf("unknown resource access protocol %q", name)
+	}
+}
+
+// lockProtocol grants units as long as they are available. Without any other
+// rule, a job blocked on a resource can wait for any number of lower priority
+// jobs (priority inversion), and nested critical sections can deadlock. Under
+// non-preemptive critical sections (NPCS) a job holding resources cannot be
+// preempted; under the Priority Inheritance Protocol (PIP) it runs with the
+// priority of the jobs it blocks.
+type lockProtocol struct {
+	nonPreemptive bool
+	inherit       bool
+}
+
+func (p lockProtocol) Admits(job *Job, state ResourceState) bool {
+	return true
+}
+
+func (p lockProtocol) Lock(job *Job, resourceID, units int, state ResourceState) (bool, int) {
+	return units <= state.Available(resourceID), resourceID
+}
+
+func (p lockProtocol) Preemptible(job *Job) bool {
+	return !p.nonPreemptive || len(job.held) == 0
+}
+
+func (p lockProtocol) Inherits() bool {
+	return p.inherit
+}
+
+func (p lockProtocol) PreventsDeadlock() bool {
+	return p.nonPreemptive
+}
+
+// pcpProtocol is the Priority Ceiling Protocol with the multi-unit ceilings of
+// the resources, computed from preemption levels. A job may only lock units if
+// its preemption level is higher than the ceiling of the resources held by the
+// other jobs, and the jobs holding resources inherit the priority of the jobs
+// they block.
+type pcpProtocol struct{}
+
+func (p pcpProtocol) Admits(job *Job, state ResourceState) bool {
+	return true
+}
+
+func (p pcpProtocol) Lock(job *Job, resourceID, units int, state ResourceState) (bool, int) {
+	if ceiling, blocker := state.Ceiling(job); job.Task.PreemptionLevel >= ceiling {
+		return false, blocker
+	}
+	return units <= state.Available(resourceID), resourceID
+}
+
+func (p pcpProtocol) Preemptible(job *Job) bool {
+	return true
+}
+
+func (p pcpProtocol) Inherits() bool {
+	return true
+}
+
+func (p pcpProtocol) PreventsDeadlock() bool {
+	return true
+}
+
+// srpProtocol is the multi-unit Stack Resource Policy: a job may only start if
+// its preemption level is higher than the system ceiling, after which the units
+// it requests are always available if the preemption levels are consistent.
+type srpProtocol struct{}
+
+func (p srpProtocol) Admits(job *Job, state ResourceState) bool {
+	ceiling, _ := state.Ceiling(nil)
+	return job.started || job.Task.PreemptionLevel < ceiling
+}
+
+func (p srpProtocol) Lock(job *Job, resourceID, units int, state ResourceState) (bool, int) {
+	return units <= state.Available(resourceID), resourceID
+}
+
+func (p srpProtocol) Preemptible(job *Job) bool {
+	return true
+}
+
+func (p srpProtocol) Inherits() bool {
+	return false
+}
+
+func (p srpProtocol) PreventsDeadlock() bool {
+	return true
+}
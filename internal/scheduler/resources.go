@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"math"
+	"sort"
+
+	"github.com/99109766/fms-scheduler/internal/trace"
+)
+
+// Available returns the number of free units of the resource.
+func (s *simulator) Available(resourceID int) int {
+	return s.available[resourceID]
+}
+
+// Ceiling returns the current ceiling of the resources given the number of units
+// available, counting the units held by the excluded job, if any, as available.
+func (s *simulator) Ceiling(exclude *Job) (int, int) {
+	ceiling, resourceID := math.MaxInt32, 0
+	for _, r := range s.resourceList {
+		available := s.available[r.ID]
+		if exclude != nil {
+			available += exclude.held[r.ID]
+		}
+		if c := r.CeilingAt(available); c < ceiling {
+			ceiling, resourceID = c, r.ID
+		}
+	}
+	return ceiling, resourceID
+}
+
+// systemCeiling returns the current system ceiling of the Stack Resource Policy:
+// the highest (i.e. numerically lowest) ceiling of any resource given the number
+// of units currently available. math.MaxInt32 means no resource is held.
+func (s *simulator) systemCeiling() int {
+	ceiling, _ := s.Ceiling(nil)
+	return ceiling
+}
+
+// acquireResources updates the units held by the job to match the critical
+// sections active at its current execution point. Units of the critical sections
+// the job left are released first. The resources of the ones it entered are then
+// acquired in nesting order, enclosing sections first, as long as the resource
+// access protocol grants them. It returns false if some requested units are not
+// granted, and records the resource the job waits for while it keeps holding the
+// units acquired so far.
+func (s *simulator) acquireResources(job *Job) bool {
+	demand, order := job.demandedUnits()
+	s.releaseResources(job, demand)
+
+	for _, resourceID := range order {
+		extra := demand[resourceID] - job.held[resourceID]
+		if extra <= 0 {
+			continue
+		}
+		if ok, blocker := s.protocol.Lock(job, resourceID, extra, s); !ok {
+			var e trace.Event
+			if blocker == resourceID {
+				e = jobEvent(trace.ResourceBlock, job)
+				e.ResourceID, e.Units, e.Available = resourceID, extra, s.available[resourceID]
+			} else {
+				// Blocked by the ceiling of a resource held by another job.
+				e = jobEvent(trace.CeilingBlock, job)
+				e.ResourceID = blocker
+				e.SystemCeiling, _ = s.Ceiling(job)
+			}
+			s.emit(e)
+			s.noteInjectedBlocking(job)
+			job.waitFor = blocker
+			return false
+		}
+
+		s.available[resourceID] -= extra
+		s.setHeld(job, resourceID, demand[resourceID])
+		e := jobEvent(trace.CSEnter, job)
+		e.ResourceID, e.Units, e.Available, e.SystemCeiling = resourceID, extra, s.available[resourceID], s.systemCeiling()
+		s.emit(e)
+	}
+
+	job.waitFor = 0
+	return true
+}
+
+// releaseResources releases the units the job holds beyond the given demand and
+// wakes up the jobs blocked on resources.
+func (s *simulator) releaseResources(job *Job, demand map[int]int) {
+	released := false
+	for _, resourceID := range sortedKeys(job.held) {
+		if surplus := job.held[resourceID] - demand[resourceID]; surplus > 0 {
+			s.available[resourceID] += surplus
+			s.setHeld(job, resourceID, demand[resourceID])
+			released = true
+			e := jobEvent(trace.CSExit, job)
+			e.ResourceID, e.Units, e.Available, e.SystemCeiling = resourceID, surplus, s.available[resourceID], s.systemCeiling()
+			s.emit(e)
+		}
+	}
+
+	if released && len(s.blockedJobs) > 0 {
+		s.readyQueue = append(s.readyQueue, s.blockedJobs...)
+		s.blockedJobs = s.blockedJobs[:0]
+	}
+}
+
+// setHeld sets the number of units of the resource held by the job and records
+// the change in the hold intervals.
+func (s *simulator) setHeld(job *Job, resourceID, units int) {
+	if i, ok := job.holds[resourceID]; ok {
+		s.holds[i].End = s.currentTime
+		delete(job.holds, resourceID)
+	}
+	if units == 0 {
+		delete(job.held, resourceID)
+		return
+	}
+
+	job.held[resourceID] = units
+	job.holds[resourceID] = len(s.holds)
+	s.holds = append(s.holds, Hold{
+		JobID:      job.JobID,
+		TaskID:     job.Task.ID,
+		ResourceID: resourceID,
+		Units:      units,
+		Start:      s.currentTime,
+		End:        math.Inf(1),
+	})
+}
+
+// holdRecords returns the hold intervals, closing the ones still open at the
+// end of the simulation.
+func (s *simulator) holdRecords() []Hold {
+	for i := range s.holds {
+		if math.IsInf(s.holds[i].End, 1) {
+			s.holds[i].End = s.currentTime
+		}
+	}
+	return s.holds
+}
+
+// sortedKeys returns the keys of the map in ascending order.
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,294 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/99109766/fms-scheduler/internal/scenario"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+	"github.com/99109766/fms-scheduler/internal/trace"
+)
+
+// InjectionReport describes how the system responded to an injected event.
+type InjectionReport struct {
+	Injection scenario.Injection `json:"injection"`
+	Applied   bool               `json:"applied"`
+	JobID     int                `json:"job_id,omitempty"`
+	Time      float64            `json:"time"`
+	Demand    float64            `json:"demand"`
+
+	// Capped tells whether the injections asked the job for more than its
+	// budget, Requested, in which case the job only executes its budget.
+	Capped    bool    `json:"capped,omitempty"`
+	Requested float64 `json:"requested,omitempty"`
+
+	// ModeSwitch tells whether the job triggered the switch to Overrun mode, in
+	// which case DroppedJobs LC jobs were dropped by the switch.
+	ModeSwitch  bool `json:"mode_switch"`
+	DroppedJobs int  `json:"dropped_jobs"`
+
+	// CeilingBlocks counts the jobs blocked, by the system ceiling or on the
+	// resource, while the job held the resource of an extended critical section.
+	CeilingBlocks int `json:"ceiling_blocks"`
+
+	DeadlineMiss bool `json:"deadline_miss"`
+
+	Outcome      string  `json:"outcome"`
+	FinishTime   float64 `json:"finish_time,omitempty"`
+	ResponseTime float64 `json:"response_time,omitempty"`
+}
+
+func (r *InjectionReport) String() string {
+	if !r.Applied {
+		return fmt.Sprintf("%v: %s", r.Injection, r.Outcome)
+	}
+
+	s := fmt.Sprintf("%v: applied to Job %d at %.3f (Demand=%.3f)", r.Injection, r.JobID, r.Time, r.Demand)
+	if r.Capped {
+		s = fmt.Sprintf("%v: applied to Job %d at %.3f (Demand=%.3f, capped from %.3f)",
+			r.Injection, r.JobID, r.Time, r.Demand, r.Requested)
+	}
+	if r.ModeSwitch {
+		s += fmt.Sprintf(", switched to OVERRUN dropping %d LC jobs", r.DroppedJobs)
+	}
+	if r.Injection.Kind == scenario.HoldExtension {
+		s += fmt.Sprintf(", blocked %d jobs", r.CeilingBlocks)
+	}
+	if r.DeadlineMiss {
+		s += ", missed its deadline"
+	}
+	s += ", " + r.Outcome
+	if r.Outcome == OutcomeCompleted {
+		s += fmt.Sprintf(" at %.3f (ResponseTime=%.3f)", r.FinishTime, r.ResponseTime)
+	}
+	return s
+}
+
+// WithScenario injects the events of the scenario into the simulation. The
+// result reports the response of the system to each of them.
+func WithScenario(sc *scenario.Scenario) Option {
+	return func(s *simulator) {
+		for _, injection := range sc.Injections {
+			s.injections = append(s.injections, &InjectionReport{Injection: injection})
+		}
+	}
+}
+
+// checkInjections verifies that the injections target existing tasks and
+// resources, and that the budget of the targeted task can honour them: LC tasks
+// cannot overrun, an injected execution time must not exceed the budget, and an
+// extended critical section must still end within it.
+func (s *simulator) checkInjections() error {
+	byID := make(map[int]*tasks.Task)
+	for _, t := range s.taskSet {
+		byID[t.ID] = t
+	}
+	for _, r := range s.injections {
+		injection := r.Injection
+		t, ok := byID[injection.TaskID]
+		if !ok {
+			return fmt.Errorf("scenario injection targets unknown task %d", injection.TaskID)
+		}
+
+		limit := maxDemand(t)
+		switch injection.Kind {
+		case scenario.ExecutionTime:
+			if injection.ExecTime > limit+epsilon {
+				return fmt.Errorf("scenario injection %q exceeds the budget %.3f of task %d", injection, limit, t.ID)
+			}
+		case scenario.Overrun:
+			if t.Criticality != tasks.HC {
+				return fmt.Errorf("scenario injection %q: LC task %d cannot overrun", injection, t.ID)
+			}
+			if demand := t.WCET1 * (1 + injection.Overrun); demand > limit+epsilon {
+				return fmt.Errorf("scenario injection %q asks for %.3f, above the budget %.3f of task %d", injection, demand, limit, t.ID)
+			}
+		case scenario.HoldExtension:
+			if _, ok := s.available[injection.ResourceID]; !ok {
+				return fmt.Errorf("scenario injection targets unknown resource %d", injection.ResourceID)
+			}
+			sections := extendSections(t.CriticalSections, injection.ResourceID, injection.Extension)
+			if sections == nil {
+				return fmt.Errorf("scenario injection %q: task %d does not use resource %d", injection, t.ID, injection.ResourceID)
+			}
+			for _, cs := range sections {
+				if cs.End() > limit+epsilon {
+					return fmt.Errorf("scenario injection %q makes a critical section end at %.3f, past the budget %.3f of task %d",
+						injection, cs.End(), limit, t.ID)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// takeInjections marks the pending injections of the given kind that target
+// the index-th job of the task, released at the given time, as applied and
+// returns them.
+func (s *simulator) takeInjections(kind string, t *tasks.Task, index int, releaseTime float64) []*InjectionReport {
+	var taken []*InjectionReport
+	for _, r := range s.injections {
+		if !r.Applied && r.Injection.Kind == kind && r.Injection.Matches(t.ID, index, releaseTime+epsilon) {
+			r.Applied = true
+			r.Time = s.currentTime
+			taken = append(taken, r)
+		}
+	}
+	return taken
+}
+
+// delayRelease delays the release of the next job of the task if the scenario
+// asks for it. It returns false if the release is due now.
+func (s *simulator) delayRelease(t *tasks.Task, releaseTime float64, epoch int) bool {
+	if !s.releases(t) {
+		return false
+	}
+
+	delay := 0.0
+	for _, r := range s.takeInjections(scenario.DelayedRelease, t, s.jobIndex[t.ID]+1, releaseTime) {
+		delay += r.Injection.Delay
+		s.delayed[t.ID] = append(s.delayed[t.ID], r)
+	}
+	if delay <= 0 {
+		return false
+	}
+
+	s.emit(trace.Event{Kind: trace.Inject, TaskID: t.ID, Amount: delay})
+	s.pushEvent(&event{time: releaseTime + delay, kind: releaseEvent, task: t, epoch: epoch})
+	return true
+}
+
+// injectJob applies the injections targeting the newly released job and
+// recomputes its demand. checkInjections guarantees that each injection fits the
+// budget of the job, but a hold extension added to a drawn demand may not: the
+// demand is then capped by the budget enforcement, as drawn demands are, and the
+// cap is recorded in the reports. The extended critical sections still end
+// within the budget, so the cap never cuts them short.
+func (s *simulator) injectJob(job *Job) {
+	t := job.Task
+	reports := s.delayed[t.ID]
+	delete(s.delayed, t.ID)
+
+	for _, r := range s.takeInjections(scenario.ExecutionTime, t, job.Index, job.ReleaseTime) {
+		job.Demand = r.Injection.ExecTime
+		reports = append(reports, r)
+	}
+	for _, r := range s.takeInjections(scenario.Overrun, t, job.Index, job.ReleaseTime) {
+		job.Demand = t.WCET1 * (1 + r.Injection.Overrun)
+		reports = append(reports, r)
+	}
+	for _, r := range s.takeInjections(scenario.HoldExtension, t, job.Index, job.ReleaseTime) {
+		job.sections = extendSections(job.sections, r.Injection.ResourceID, r.Injection.Extension)
+		job.Demand += r.Injection.Extension
+		reports = append(reports, r)
+	}
+	if len(reports) == 0 {
+		return
+	}
+
+	requested := job.Demand
+	if limit := maxDemand(t); job.Demand > limit {
+		s.emit(trace.Event{Kind: trace.Note, JobID: job.JobID, TaskID: t.ID,
+			Message: fmt.Sprintf("Job %d (Task %d) injected demand %.3f capped at its budget %.3f", job.JobID, t.ID, job.Demand, limit)})
+		job.Demand = limit
+	}
+	job.RemainingTime = job.Demand
+	for _, r := range reports {
+		r.JobID = job.JobID
+		r.Demand = job.Demand
+		if job.Demand < requested {
+			r.Capped, r.Requested = true, requested
+		}
+		e := jobEvent(trace.Inject, job)
+		e.Message = r.Injection.String()
+		s.emit(e)
+	}
+	s.injected[job] = reports
+}
+
+// extendSections returns the critical sections with the first one on the
+// resource extended by the given time. Critical sections enclosing it end later,
+// and the ones after it start later. It returns nil if none of the critical
+// sections is on the resource.
+func extendSections(sections []*tasks.CriticalSection, resourceID int, extension float64) []*tasks.CriticalSection {
+	index := -1
+	for i, cs := range sections {
+		if cs.ResourceID == resourceID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil
+	}
+
+	end := sections[index].End()
+	extended := make([]*tasks.CriticalSection, len(sections))
+	for i, cs := range sections {
+		section := *cs
+		switch {
+		case cs.Start >= end:
+			section.Start += extension
+		case cs.End() >= end:
+			section.Duration += extension
+		}
+		extended[i] = &section
+	}
+	return extended
+}
+
+// noteInjectedSwitch records that the job triggered a switch to Overrun mode
+// dropping the given number of LC jobs.
+func (s *simulator) noteInjectedSwitch(job *Job, dropped int) {
+	for _, r := range s.injected[job] {
+		r.ModeSwitch = true
+		r.DroppedJobs = dropped
+	}
+}
+
+// noteInjectedBlocking counts a blocking of the job against every extended
+// critical section held at the moment.
+func (s *simulator) noteInjectedBlocking(blocked *Job) {
+	for job, reports := range s.injected {
+		if job == blocked {
+			continue
+		}
+		for _, r := range reports {
+			if r.Injection.Kind == scenario.HoldExtension && job.held[r.Injection.ResourceID] > 0 {
+				r.CeilingBlocks++
+			}
+		}
+	}
+}
+
+// noteInjectedMiss records that a job targeted by injections missed its deadline.
+func (s *simulator) noteInjectedMiss(job *Job) {
+	for _, r := range s.injected[job] {
+		r.DeadlineMiss = true
+	}
+}
+
+// finishInjected records the outcome of a job targeted by injections.
+func (s *simulator) finishInjected(job *Job, outcome string) {
+	for _, r := range s.injected[job] {
+		r.Outcome = outcome
+		if outcome == OutcomeCompleted {
+			r.FinishTime = s.currentTime
+			r.ResponseTime = s.currentTime - job.ReleaseTime
+		}
+	}
+	delete(s.injected, job)
+}
+
+// injectionReports returns the reports of all injections, closing the ones whose
+// jobs were still pending at the end of the simulation.
+func (s *simulator) injectionReports() []*InjectionReport {
+	for job := range s.injected {
+		s.finishInjected(job, OutcomeUnfinished)
+	}
+	for _, r := range s.injections {
+		if !r.Applied {
+			r.Outcome = OutcomeNotReleased
+		}
+	}
+	return s.injections
+}
@@ -0,0 +1,145 @@
+package scheduler_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/resources"
+	"github.com/99109766/fms-scheduler/internal/scenario"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// nestedTaskSet returns an HC task with a budget of 8 holding resource 2 over
+// [0.5, 3.5), resource 1 nested in it over [1, 3), then resource 3 over [4, 4.5),
+// and an LC task without critical sections.
+func nestedTaskSet() ([]*tasks.Task, []*resources.Resource) {
+	taskSet := []*tasks.Task{
+		{ID: 1, Criticality: tasks.HC, Period: 20, Deadline: 20, WCET1: 5, WCET2: 3, CriticalSections: []*tasks.CriticalSection{
+			{ResourceID: 2, Units: 1, Start: 0.5, Duration: 3},
+			{ResourceID: 1, Units: 1, Start: 1, Duration: 2},
+			{ResourceID: 3, Units: 1, Start: 4, Duration: 0.5},
+		}},
+		{ID: 2, Criticality: tasks.LC, Period: 20, Deadline: 20, WCET1: 2},
+	}
+	resourceList := []*resources.Resource{{ID: 1, Units: 1}, {ID: 2, Units: 1}, {ID: 3, Units: 1}, {ID: 4, Units: 1}}
+	tasks.ComputePreemptionLevels(&config.Config{}, taskSet, resourceList)
+	return taskSet, resourceList
+}
+
+func TestRunSchedulerRejectsInjections(t *testing.T) {
+	tests := []struct {
+		name      string
+		injection scenario.Injection
+		err       string
+	}{
+		{
+			name:      "unknown task",
+			injection: scenario.Injection{Kind: scenario.ExecutionTime, TaskID: 3, Job: 1, ExecTime: 1},
+			err:       "unknown task 3",
+		},
+		{
+			name:      "execution time above the budget",
+			injection: scenario.Injection{Kind: scenario.ExecutionTime, TaskID: 1, Job: 1, ExecTime: 9},
+			err:       "exceeds the budget 8.000 of task 1",
+		},
+		{
+			name:      "LC overrun",
+			injection: scenario.Injection{Kind: scenario.Overrun, TaskID: 2, Job: 1, Overrun: 0.1},
+			err:       "LC task 2 cannot overrun",
+		},
+		{
+			name:      "overrun above the budget",
+			injection: scenario.Injection{Kind: scenario.Overrun, TaskID: 1, Job: 1, Overrun: 1},
+			err:       "asks for 10.000, above the budget 8.000 of task 1",
+		},
+		{
+			name:      "unknown resource",
+			injection: scenario.Injection{Kind: scenario.HoldExtension, TaskID: 1, Job: 1, ResourceID: 5, Extension: 1},
+			err:       "unknown resource 5",
+		},
+		{
+			name:      "resource not used",
+			injection: scenario.Injection{Kind: scenario.HoldExtension, TaskID: 1, Job: 1, ResourceID: 4, Extension: 1},
+			err:       "task 1 does not use resource 4",
+		},
+		{
+			name:      "section past the budget",
+			injection: scenario.Injection{Kind: scenario.HoldExtension, TaskID: 1, Job: 1, ResourceID: 1, Extension: 4},
+			err:       "makes a critical section end at 8.500, past the budget 8.000 of task 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskSet, resourceList := nestedTaskSet()
+			sc := &scenario.Scenario{Injections: []scenario.Injection{tt.injection}}
+			_, err := scheduler.RunScheduler(&config.Config{SimulateTime: 20, Quiet: true}, taskSet, resourceList, scheduler.WithScenario(sc))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("RunScheduler() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRunSchedulerHoldExtension(t *testing.T) {
+	tests := []struct {
+		name      string
+		execTable map[int][]float64
+		demand    float64
+		requested float64
+	}{
+		{
+			name:   "within the budget",
+			demand: 7,
+		},
+		{
+			// The drawn demand of 7 plus the extension exceeds the budget of 8.
+			name:      "capped",
+			execTable: map[int][]float64{1: {7}},
+			demand:    8,
+			requested: 9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskSet, resourceList := nestedTaskSet()
+			cfg := &config.Config{SimulateTime: 20, Quiet: true}
+			if tt.execTable != nil {
+				cfg.ExecModel = config.ExecModelConfig{Kind: scheduler.TableExecution, Table: tt.execTable}
+			}
+			sc := &scenario.Scenario{Injections: []scenario.Injection{
+				{Kind: scenario.HoldExtension, TaskID: 1, Job: 1, ResourceID: 1, Extension: 2},
+			}}
+			result, err := scheduler.RunScheduler(cfg, taskSet, resourceList, scheduler.WithScenario(sc))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := result.Injections[0]
+			if !r.Applied || r.Demand != tt.demand || r.Capped != (tt.requested > 0) || r.Requested != tt.requested {
+				t.Errorf("report = %+v, want demand %g requested %g", r, tt.demand, tt.requested)
+			}
+
+			// Resource 1 is held 2 units longer, resource 2 enclosing it ends 2
+			// units later, and resource 3 after it starts 2 units later.
+			var holds []scheduler.Hold
+			for _, h := range result.Holds {
+				if h.TaskID == 1 {
+					holds = append(holds, h)
+				}
+			}
+			want := []scheduler.Hold{
+				{JobID: 1, TaskID: 1, ResourceID: 2, Units: 1, Start: 0.5, End: 5.5},
+				{JobID: 1, TaskID: 1, ResourceID: 1, Units: 1, Start: 1, End: 5},
+				{JobID: 1, TaskID: 1, ResourceID: 3, Units: 1, Start: 6, End: 6.5},
+			}
+			if !reflect.DeepEqual(holds, want) {
+				t.Errorf("holds = %v, want %v", holds, want)
+			}
+		})
+	}
+}
@@ -2,200 +2,621 @@ package scheduler
 
 import (
 	"fmt"
-	"sort"
+	"math"
+	"math/rand"
+	"os"
 
+	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/resources"
 	"github.com/99109766/fms-scheduler/internal/tasks"
+	"github.com/99109766/fms-scheduler/internal/trace"
 )
 
-// Mode indicates the current system mode.
-type Mode int
+// epsilon is the tolerance used when comparing simulated time instants.
+const epsilon = 1e-9
 
-const (
-	Normal Mode = iota
-	Overrun
-)
+// simulator holds the state of a discrete-event simulation run.
+type simulator struct {
+	taskSet      []*tasks.Task
+	resourceList []*resources.Resource
+	simulateTime float64
+	modeReturn   string
+	modeDwell    float64 // time spent in Overrun mode before a time based return
+	policy       Policy
+	protocol     Protocol
+	sinks        []trace.Sink
+	execModel    ExecutionModel
+	jobIndex     map[int]int
 
-// dropLCJobs removes all low-criticality jobs from the ready queue.
-// This is used when the system switches to Overrun mode.
-func dropLCJobs(queue []*Job, currentTime float64) []*Job {
-	newQueue := []*Job{}
-	for _, job := range queue {
-		if job.Task.Criticality == tasks.HC {
-			newQueue = append(newQueue, job)
-		} else {
-			fmt.Printf("Time %.3f: Dropped LC Job %d (Task %d) due to mode switch\n",
-				currentTime, job.JobID, job.Task.ID)
-		}
+	// Injected events of a scenario: the reports of all injections, the ones
+	// applied to pending jobs, and the delays applied to upcoming releases.
+	injections []*InjectionReport
+	injected   map[*Job][]*InjectionReport
+	delayed    map[int][]*InjectionReport
+
+	// Deadline misses, the late jobs still running and the tasks whose next
+	// release is skipped.
+	missPolicy string
+	misses     []Miss
+	late       map[*Job]int
+	skipNext   map[int]bool
+
+	deadlocks  []Deadlock
+	suppressed []Suppression
+	skipped    []Suppression
+	shifted    []ShiftedRelease
+
+	// Early release (ER-EDF) state of LC tasks in Overrun mode.
+	earlyRelease     bool
+	lcMaxPeriodRatio float64
+	slack            float64
+	lastRelease      map[int]float64
+	releaseEpoch     map[int]int
+	pendingEarly     map[int]bool
+
+	currentTime  float64
+	mode         Mode
+	modeEpoch    int
+	modeSwitches []ModeSwitch
+	events       eventQueue
+	eventOrder   int
+
+	// deferredReturn is the reason of a return to Normal mode deferred until no
+	// HC job is past its WCET1 budget, or "".
+	deferredReturn string
+
+	runningJob  *Job
+	dispatch    int
+	readyQueue  []*Job
+	blockedJobs []*Job
+	available   map[int]int
+	jobCounter  int
+	jobs        []*Job
+	holds       []Hold
+	schedule    []Schedule
+}
+
+// Option customises a simulation run beyond what the configuration describes.
+type Option func(*simulator)
+
+// WithExecutionModel replaces the execution model built from cfg.ExecModel.
+func WithExecutionModel(model ExecutionModel) Option {
+	return func(s *simulator) {
+		s.execModel = model
+	}
+}
+
+// WithPolicy replaces the scheduling policy selected by cfg.Algorithm.
+func WithPolicy(policy Policy) Option {
+	return func(s *simulator) {
+		s.policy = policy
+	}
+}
+
+// WithProtocol replaces the resource access protocol selected by cfg.Protocol.
+func WithProtocol(protocol Protocol) Option {
+	return func(s *simulator) {
+		s.protocol = protocol
 	}
-	return newQueue
 }
 
-// extendRemainingTime extends the remaining time of all HC jobs in the ready queue.
-// This is used when the system switches to Overrun mode.
-func extendRemainingTime(jobs []*Job) {
-	for _, job := range jobs {
-		job.RemainingTime += job.Task.WCET2
+// WithSink sends the simulation events to the sink. Without any sink, events are
+// logged to the standard output unless cfg.Quiet is set.
+func WithSink(sink trace.Sink) Option {
+	return func(s *simulator) {
+		s.sinks = append(s.sinks, sink)
 	}
 }
 
-// RunScheduler simulates an ER-EDF scheduler for a mixed-criticality system.
-// It releases jobs from the task set and simulates execution for simTime seconds.
-func RunScheduler(taskSet []*tasks.Task, simulateTime float64) ([]Schedule, error) {
-	// Map each task to its next release time.
-	nextRelease := make(map[int]float64)
+// RunScheduler simulates the scheduling of a mixed-criticality task set on one
+// processor for cfg.SimulateTime time units. Jobs are ordered by the policy
+// selected by cfg.Algorithm: EDF (the default), EDF-VD, deadline-monotonic,
+// rate-monotonic or fixed-priority AMC. LC tasks keep running in Overrun mode
+// with ER-EDF early release if cfg.EarlyRelease is set, and the system returns
+// to Normal mode according to cfg.ModeReturn. The actual execution time of each
+// job is drawn from the execution model, whose random source is seeded with
+// cfg.Seed. Access to the shared resources is arbitrated by the protocol
+// selected by cfg.Protocol, the multi-unit Stack Resource Policy by default,
+// using the preemption levels of the tasks and the ceilings of the resources.
+//
+// The simulation is event driven: time jumps directly to the next job release,
+// completion, critical section boundary, deadline or WCET1 budget exhaustion,
+// since the scheduling decision cannot change in between.
+func RunScheduler(cfg *config.Config, taskSet []*tasks.Task, resourceList []*resources.Resource, opts ...Option) (*Result, error) {
+	s := &simulator{
+		taskSet:      taskSet,
+		resourceList: resourceList,
+		simulateTime: cfg.SimulateTime,
+		modeReturn:   cfg.ModeReturn,
+		modeDwell:    cfg.ModeDwell,
+		execModel:    NewExecutionModel(cfg.ExecModel, rand.New(rand.NewSource(cfg.Seed))),
+		jobIndex:     make(map[int]int),
+		injected:     make(map[*Job][]*InjectionReport),
+		delayed:      make(map[int][]*InjectionReport),
+		missPolicy:   cfg.MissPolicy,
+		misses:       make([]Miss, 0),
+		late:         make(map[*Job]int),
+		skipNext:     make(map[int]bool),
+		earlyRelease: cfg.EarlyRelease,
+		mode:         Normal,
+		modeSwitches: make([]ModeSwitch, 0),
+		readyQueue:   make([]*Job, 0),
+		available:    make(map[int]int),
+		holds:        make([]Hold, 0),
+		schedule:     make([]Schedule, 0),
+
+		lcMaxPeriodRatio: math.Max(cfg.LCMaxPeriodRatio, 1),
+		lastRelease:      make(map[int]float64),
+		releaseEpoch:     make(map[int]int),
+		pendingEarly:     make(map[int]bool),
+	}
+	if s.missPolicy == "" {
+		s.missPolicy = MissAbort
+	}
+	if s.modeReturn == ReturnHyperperiod {
+		s.modeDwell = tasks.Hyperperiod(taskSet)
+		if math.IsInf(s.modeDwell, 1) {
+			return nil, fmt.Errorf("mode_return %q needs commensurable task periods, but the hyperperiod of the task set overflows", ReturnHyperperiod)
+		}
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.policy == nil {
+		policy, err := NewPolicy(cfg.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		s.policy = policy
+	}
+	if s.protocol == nil {
+		protocol, err := NewProtocol(cfg.Protocol)
+		if err != nil {
+			return nil, err
+		}
+		s.protocol = protocol
+	}
+	if len(s.sinks) == 0 && !cfg.Quiet {
+		s.sinks = append(s.sinks, trace.NewConsoleSink(os.Stdout))
+	}
+
+	for _, r := range resourceList {
+		s.available[r.ID] = r.Units
+	}
+	if err := s.checkInjections(); err != nil {
+		return nil, err
+	}
+	s.emit(trace.Event{Kind: trace.Note, Message: fmt.Sprintf("Random seed %d", cfg.Seed)})
+
+	if note := s.policy.Setup(taskSet); note != "" {
+		s.emit(trace.Event{Kind: trace.Note, Message: note})
+	}
+	if s.earlyRelease && s.policy.SuspendsLC() {
+		s.earlyRelease = false
+		s.emit(trace.Event{Kind: trace.Note, Message: "LC tasks are suspended in Overrun mode, early release is disabled"})
+	}
+
 	for _, t := range taskSet {
-		nextRelease[t.ID] = 0
-	}
-
-	var runningJob *Job
-	mode, dt := Normal, 1e-5
-	readyQueue := make([]*Job, 0)
-	scheduler := make([]Schedule, 0)
-	jobCounter, runningJobInCS := 0, false
-
-	for currentTime := 0.0; currentTime < simulateTime; currentTime += dt {
-		// Release new jobs if their release time has arrived.
-		for _, t := range taskSet {
-			if currentTime >= nextRelease[t.ID] {
-				// In Overrun mode, only release HC tasks.
-				if mode == Normal || (mode == Overrun && t.Criticality == tasks.HC) {
-					jobCounter++
-					newJob := &Job{
-						Task:             t,
-						JobID:            jobCounter,
-						ReleaseTime:      nextRelease[t.ID],
-						AbsoluteDeadline: nextRelease[t.ID] + t.Deadline,
-						RemainingTime:    t.WCET1,
-						ExecTime:         0,
-					}
-					// For HC tasks, choose WCET1 in Normal mode and WCET1+WCET2 in Overrun mode.
-					if t.Criticality == tasks.HC && mode == Overrun {
-						newJob.RemainingTime += t.WCET2
-					}
-
-					readyQueue = append(readyQueue, newJob)
-					fmt.Printf("Time %.3f: Released Job %d (Task %d, Deadline=%.3f, WCET=%.3f) [Mode: %v]\n",
-						currentTime, newJob.JobID, t.ID, newJob.AbsoluteDeadline, newJob.RemainingTime, mode)
-				}
+		s.pushEvent(&event{time: 0, kind: releaseEvent, task: t})
+	}
 
-				// Schedule the next release for the task.
-				nextRelease[t.ID] += t.Period
-			}
+	for {
+		next := s.simulateTime
+		if e := s.events.peek(); e != nil && e.time < next {
+			next = e.time
 		}
 
-		// Check if any job has missed its deadline.
-		for i := 0; i < len(readyQueue); i++ {
-			job := readyQueue[i]
-			if currentTime >= job.AbsoluteDeadline {
-				fmt.Printf("Time %.3f: MISSED Deadline for Job %d (Task %d) [Deadline=%.3f, FinishTime=%.3f]\n",
-					currentTime, job.JobID, job.Task.ID, job.AbsoluteDeadline, currentTime)
-				return nil, fmt.Errorf("deadline missed for job %d (task %d)", job.JobID, job.Task.ID)
-			}
+		s.execute(next - s.currentTime)
+		s.currentTime = next
+		if s.currentTime >= s.simulateTime {
+			break
 		}
 
-		// Scheduler decision: select a job to run.
-		if len(readyQueue) > 0 {
-			sort.Slice(readyQueue, func(i, j int) bool {
-				return readyQueue[i].effectivePriority() < readyQueue[j].effectivePriority()
-			})
+		for e := s.events.peek(); e != nil && e.time <= s.currentTime+epsilon; e = s.events.peek() {
+			s.handle(s.events.pop())
+		}
 
-			if runningJob == nil {
-				// Pick the job with the smallest effective priority.
-				runningJob, readyQueue = readyQueue[0], readyQueue[1:]
-				fmt.Printf("Time %.3f: Starting Job %d (Task %d) with Deadline=%.3f, EffectivePriority=%.3f\n",
-					currentTime, runningJob.JobID, runningJob.Task.ID, runningJob.AbsoluteDeadline, runningJob.effectivePriority())
+		s.selectJob()
+		s.checkDeferredReturn()
+		s.checkIdleReturn()
+	}
 
-				if cs := runningJob.getActiveCriticalSection(); cs != nil {
-					fmt.Printf("Time %.3f: Job %d ENTERS critical section on Resource %d (CS: Start=%.3f, Duration=%.3f)\n",
-						currentTime, runningJob.JobID, cs.ResourceID, cs.Start, cs.Duration)
-				}
-			} else {
-				// Check if a waiting job has a lower effective priority.
-				candidate := readyQueue[0]
-				if candidate.effectivePriority() < runningJob.effectivePriority() {
-					// If runningJob is in a critical section, allow preemption only if candidate beats its preemption level.
-					if runningJob.getActiveCriticalSection() != nil {
-						if candidate.effectivePriority() < float64(runningJob.Task.PreemptionLevel) {
-							fmt.Printf("Time %.3f: Preempting Job %d (Task %d, EffPri=%.3f, in CS) with Job %d (Task %d, EffPri=%.3f)\n",
-								currentTime, runningJob.JobID, runningJob.Task.ID, runningJob.effectivePriority(),
-								candidate.JobID, candidate.Task.ID, candidate.effectivePriority())
-
-							readyQueue = append(readyQueue, runningJob)
-							runningJob, readyQueue = candidate, readyQueue[1:]
-							if cs := runningJob.getActiveCriticalSection(); cs != nil {
-								fmt.Printf("Time %.3f: Job %d ENTERS critical section on Resource %d (CS: Start=%.3f, Duration=%.3f)\n",
-									currentTime, runningJob.JobID, cs.ResourceID, cs.Start, cs.Duration)
-							}
-						}
-					} else {
-						fmt.Printf("Time %.3f: Preempting Job %d (Task %d, EffPri=%.3f) with Job %d (Task %d, EffPri=%.3f)\n",
-							currentTime, runningJob.JobID, runningJob.Task.ID, runningJob.effectivePriority(),
-							candidate.JobID, candidate.Task.ID, candidate.effectivePriority())
-
-						runningJob, readyQueue = candidate, readyQueue[1:]
-						if cs := runningJob.getActiveCriticalSection(); cs != nil {
-							fmt.Printf("Time %.3f: Job %d ENTERS critical section on Resource %d (CS: Start=%.3f, Duration=%.3f)\n",
-								currentTime, runningJob.JobID, cs.ResourceID, cs.Start, cs.Duration)
-						}
-					}
-				}
+	return &Result{
+		Algorithm:    valueOr(cfg.Algorithm, tasks.EDF),
+		Protocol:     valueOr(cfg.Protocol, ProtocolSRP),
+		EarlyRelease: s.earlyRelease,
+		Seed:         cfg.Seed,
+		SimulateTime: s.simulateTime,
+		Schedule:     s.schedule,
+		Holds:        s.holdRecords(),
+		ModeSwitches: s.modeSwitches,
+		Misses:       s.missReports(),
+		Jobs:         s.jobRecords(),
+		Deadlocks:    s.deadlocks,
+		Suppressed:   s.suppressed,
+		Skipped:      s.skipped,
+		Shifted:      s.shifted,
+		Injections:   s.injectionReports(),
+	}, nil
+}
+
+// maxDemand returns the largest demand the budget enforcement lets a job of the
+// task execute: WCET1 for LC tasks and WCET1+WCET2 for HC tasks.
+func maxDemand(t *tasks.Task) float64 {
+	if t.Criticality == tasks.HC {
+		return t.WCET1 + t.WCET2
+	}
+	return t.WCET1
+}
+
+// valueOr returns the value, or the default if the value is empty.
+func valueOr(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// emit stamps the event with the current time and mode and passes it to the sinks.
+func (s *simulator) emit(e trace.Event) {
+	if len(s.sinks) == 0 {
+		return
+	}
+	e.Time = s.currentTime
+	e.Mode = s.mode.String()
+	for _, sink := range s.sinks {
+		sink.Emit(e)
+	}
+}
+
+// jobEvent returns an event of the given kind describing the job.
+func jobEvent(kind trace.Kind, job *Job) trace.Event {
+	return trace.Event{
+		Kind:            kind,
+		JobID:           job.JobID,
+		TaskID:          job.Task.ID,
+		Deadline:        job.AbsoluteDeadline,
+		VirtualDeadline: job.VirtualDeadline,
+		Budget:          job.Budget,
+		Demand:          job.Demand,
+		ExecTime:        job.ExecTime,
+		PreemptionLevel: job.Task.PreemptionLevel,
+	}
+}
+
+// jobRecords returns the execution records of all released jobs.
+func (s *simulator) jobRecords() []JobRecord {
+	records := make([]JobRecord, len(s.jobs))
+	for i, job := range s.jobs {
+		records[i] = job.record()
+	}
+	return records
+}
+
+// pushEvent adds an event to the event queue.
+func (s *simulator) pushEvent(e *event) {
+	s.eventOrder++
+	e.order = s.eventOrder
+	s.events.push(e)
+}
+
+// execute runs the current job for the given duration and records it in the schedule.
+func (s *simulator) execute(duration float64) {
+	if s.runningJob == nil || duration <= 0 {
+		return
+	}
+
+	s.runningJob.ExecTime += duration
+	s.runningJob.RemainingTime -= duration
+
+	// Pending jobs with a higher priority than the running job are blocked.
+	for _, queue := range [][]*Job{s.readyQueue, s.blockedJobs} {
+		for _, job := range queue {
+			if s.policy.Priority(job) < s.policy.Priority(s.runningJob) {
+				job.blocking += duration
 			}
 		}
+	}
+
+	endTime := s.currentTime + duration
+	if n := len(s.schedule); n > 0 && s.schedule[n-1].JobID == s.runningJob.JobID && s.schedule[n-1].EndTime == s.currentTime {
+		s.schedule[n-1].EndTime = endTime
+	} else {
+		s.schedule = append(s.schedule, Schedule{
+			TaskID:    s.runningJob.Task.ID,
+			JobID:     s.runningJob.JobID,
+			StartTime: s.currentTime,
+			EndTime:   endTime,
+		})
+	}
+}
+
+// handle processes a single event.
+func (s *simulator) handle(e *event) {
+	switch e.kind {
+	case releaseEvent:
+		if e.epoch != s.releaseEpoch[e.task.ID] {
+			// The task was released early in the meantime.
+			return
+		}
+		if s.delayRelease(e.task, e.time, e.epoch) {
+			return
+		}
+		if s.mode == Overrun && s.earlyRelease && e.task.Criticality == tasks.LC {
+			s.releaseElastic(e.task, e.time)
+		} else {
+			s.release(e.task, e.time)
+		}
+
+	case deadlineEvent:
+		job := e.job
+		if job.AbsoluteDeadline > e.time+epsilon {
+			// The deadline was extended since the event was created.
+			return
+		}
+		if _, late := s.late[job]; !job.done && !late {
+			s.missDeadline(job)
+		}
+
+	case modeReturnEvent:
+		if s.mode == Overrun && e.epoch == s.modeEpoch {
+			s.requestReturn(s.modeReturn)
+		}
+
+	case completionEvent, csBoundaryEvent, budgetEvent:
+		if e.job != s.runningJob || e.dispatch != s.dispatch {
+			// The job was preempted or finished since the event was created.
+			return
+		}
+		s.handleRunningJobEvent(e)
+	}
+}
+
+// release creates a new job of the task at the given release time and schedules
+// the next release of the task.
+func (s *simulator) release(t *tasks.Task, releaseTime float64) {
+	nominal := 0.0
+	if last, ok := s.lastRelease[t.ID]; ok {
+		nominal = last + t.Period
+	}
+	if releaseTime > nominal+epsilon {
+		s.shifted = append(s.shifted, ShiftedRelease{Time: releaseTime, Nominal: nominal, TaskID: t.ID})
+	}
+
+	if s.releases(t) && s.skipNext[t.ID] {
+		delete(s.skipNext, t.ID)
+		s.skipped = append(s.skipped, Suppression{Time: releaseTime, TaskID: t.ID, Mode: s.mode})
+		s.emit(trace.Event{Kind: trace.Skip, TaskID: t.ID})
+	} else if s.releases(t) {
+		s.jobCounter++
+		s.jobIndex[t.ID]++
+		newJob := &Job{
+			Task:             t,
+			JobID:            s.jobCounter,
+			Index:            s.jobIndex[t.ID],
+			ReleaseTime:      releaseTime,
+			AbsoluteDeadline: releaseTime + t.Deadline,
+			Budget:           t.WCET1,
+			ExecTime:         0,
+			sections:         t.CriticalSections,
+			held:             make(map[int]int),
+			holds:            make(map[int]int),
+			releaseMode:      s.mode,
+			outcome:          OutcomeUnfinished,
+		}
+		s.jobs = append(s.jobs, newJob)
+
+		// Draw the actual demand of the job, which the budget enforcement caps at
+		// WCET1 for LC jobs and at WCET1+WCET2 for HC jobs.
+		newJob.Demand = math.Min(math.Max(s.execModel.ExecutionTime(t, newJob.Index), 0), maxDemand(t))
+		newJob.RemainingTime = newJob.Demand
+		s.injectJob(newJob)
+
+		// For HC tasks, grant WCET1 in Normal mode and WCET1+WCET2 in Overrun mode.
+		if t.Criticality == tasks.HC && s.mode == Overrun {
+			newJob.Budget += t.WCET2
+		}
+		// LC jobs released in Overrun mode run against their elastic deadlines.
+		if t.Criticality == tasks.LC && s.mode == Overrun {
+			newJob.AbsoluteDeadline = releaseTime + s.elasticDeadline(t)
+		}
+		s.policy.Release(newJob, s.mode)
+
+		s.readyQueue = append(s.readyQueue, newJob)
+		s.pushEvent(&event{time: newJob.AbsoluteDeadline, kind: deadlineEvent, job: newJob})
+		s.emit(jobEvent(trace.Release, newJob))
+	} else {
+		s.suppressed = append(s.suppressed, Suppression{Time: releaseTime, TaskID: t.ID, Mode: s.mode})
+	}
+
+	// Schedule the next release for the task.
+	s.lastRelease[t.ID] = releaseTime
+	s.releaseEpoch[t.ID]++
+	s.pendingEarly[t.ID] = false
+	s.pushEvent(&event{time: releaseTime + t.Period, kind: releaseEvent, task: t, epoch: s.releaseEpoch[t.ID]})
+}
+
+// releases reports whether jobs of the task are released in the current mode.
+// In Overrun mode, only HC tasks are released, unless LC tasks are released at
+// extended periods.
+func (s *simulator) releases(t *tasks.Task) bool {
+	return s.mode == Normal || t.Criticality == tasks.HC || s.earlyRelease
+}
+
+// handleRunningJobEvent processes a completion, critical section boundary or
+// budget exhaustion event of the running job.
+func (s *simulator) handleRunningJobEvent(e *event) {
+	job := s.runningJob
+
+	switch e.kind {
+	case csBoundaryEvent:
+		// Snap the execution time onto the boundary to avoid accumulated rounding errors.
+		job.RemainingTime += job.ExecTime - e.point
+		job.ExecTime = e.point
+		if !s.acquireResources(job) {
+			s.block(job)
+			return
+		}
+		s.scheduleCSBoundary()
+
+	case budgetEvent:
+		// Check if an HC job overruns its normal (WCET1) execution in Normal mode.
+		if job.Task.Criticality == tasks.HC && s.mode == Normal && job.RemainingTime > epsilon {
+			s.switchToOverrun(job)
+		}
 
-		// Check and log critical section entry/exit transitions.
-		if runningJob != nil {
-			cs := runningJob.getActiveCriticalSection()
-			if !runningJobInCS && cs != nil {
-				fmt.Printf("Time %.3f: Job %d (Task %d) ENTERS critical section on Resource %d (CS: Start=%.3f, Duration=%.3f)\n",
-					currentTime, runningJob.JobID, runningJob.Task.ID, cs.ResourceID, cs.Start, cs.Duration)
-			} else if runningJobInCS && cs == nil {
-				fmt.Printf("Time %.3f: Job %d (Task %d) EXITS critical section\n", currentTime, runningJob.JobID, runningJob.Task.ID)
+	case completionEvent:
+		job.ExecTime, job.RemainingTime = e.point, 0
+		job.done = true
+		job.finishTime, job.outcome = s.currentTime, OutcomeCompleted
+		s.emit(jobEvent(trace.Complete, job))
+		s.runningJob = nil
+		s.finishInjected(job, OutcomeCompleted)
+		s.completeLate(job)
+		s.releaseResources(job, nil)
+		if job.Task.Criticality == tasks.HC && s.mode == Overrun && s.earlyRelease {
+			s.reclaimSlack(job)
+		}
+	}
+}
+
+// selectJob picks the highest priority job among the ones the resource access
+// protocol admits and, if it may preempt the running job, preempts it.
+func (s *simulator) selectJob() {
+	for {
+		best := -1
+		for i, job := range s.readyQueue {
+			if !s.protocol.Admits(job, s) {
+				if !job.ceilingBlocked {
+					job.ceilingBlocked = true
+					e := jobEvent(trace.CeilingBlock, job)
+					e.SystemCeiling = s.systemCeiling()
+					s.emit(e)
+					s.noteInjectedBlocking(job)
+				}
+				continue
 			}
-			runningJobInCS = (cs != nil)
-		}
-
-		// Execute the running job for one time step.
-		if runningJob != nil {
-			runningJob.ExecTime += dt
-			runningJob.RemainingTime -= dt
-			if len(scheduler) > 0 && scheduler[len(scheduler)-1].TaskID == runningJob.Task.ID && scheduler[len(scheduler)-1].EndTime == currentTime {
-				scheduler[len(scheduler)-1].EndTime += dt
-			} else {
-				scheduler = append(scheduler, Schedule{
-					TaskID:    runningJob.Task.ID,
-					StartTime: currentTime,
-					EndTime:   currentTime + dt,
-				})
+			job.ceilingBlocked = false
+			if best < 0 || s.priority(job) < s.priority(s.readyQueue[best]) {
+				best = i
 			}
+		}
+		if best < 0 {
+			return
+		}
+		candidate := s.readyQueue[best]
 
-			// Check if the job misses its deadline.
-			if currentTime > runningJob.AbsoluteDeadline {
-				fmt.Printf("Time %.3f: MISSED Deadline for Job %d (Task %d) [Deadline=%.3f, ExecTime=%.3f]\n",
-					currentTime, runningJob.JobID, runningJob.Task.ID, runningJob.AbsoluteDeadline, runningJob.ExecTime)
-				return nil, fmt.Errorf("deadline missed for job %d (task %d)", runningJob.JobID, runningJob.Task.ID)
+		if s.runningJob == nil {
+			s.readyQueue = append(s.readyQueue[:best], s.readyQueue[best+1:]...)
+			e := jobEvent(trace.Start, candidate)
+			e.SystemCeiling = s.systemCeiling()
+			s.emit(e)
+		} else {
+			if !s.protocol.Preemptible(s.runningJob) || !s.preempts(candidate, s.runningJob) {
+				return
 			}
 
-			// Check if an HC job overruns its normal (WCET1) execution in Normal mode.
-			if runningJob.Task.Criticality == tasks.HC && mode == Normal && runningJob.ExecTime > runningJob.Task.WCET1 {
-				mode = Overrun
-				fmt.Printf("Time %.3f: Mode switch to OVERRUN triggered by Job %d (Task %d) [ExecTime=%.3f, WCET1=%.3f]\n",
-					currentTime, runningJob.JobID, runningJob.Task.ID, runningJob.ExecTime, runningJob.Task.WCET1)
+			e := jobEvent(trace.Preempt, s.runningJob)
+			e.OtherJobID, e.OtherTaskID, e.OtherDeadline = candidate.JobID, candidate.Task.ID, candidate.VirtualDeadline
+			s.emit(e)
+			s.runningJob.preemptions++
+			s.readyQueue[best] = s.runningJob
+			s.runningJob = nil
+		}
 
-				// Drop pending LC jobs.
-				readyQueue = dropLCJobs(readyQueue, currentTime)
+		if s.start(candidate) {
+			return
+		}
+	}
+}
 
-				// Extend the remaining time of the HC jobs to include WCET2.
-				extendRemainingTime(readyQueue)
-			}
+// priority returns the priority the job runs with: its priority under the
+// scheduling policy or, if the protocol has jobs inherit priorities, the highest
+// priority of the jobs it blocks, directly or transitively, if higher.
+func (s *simulator) priority(job *Job) float64 {
+	return s.inheritedPriority(job, make(map[*Job]bool))
+}
 
-			// Job completion.
-			if runningJob.RemainingTime <= 0 {
-				fmt.Printf("Time %.3f: COMPLETED Job %d (Task %d) [FinishTime=%.3f, Total ExecTime=%.3f]\n",
-					currentTime, runningJob.JobID, runningJob.Task.ID, currentTime, runningJob.ExecTime)
-				runningJob = nil
-			}
+func (s *simulator) inheritedPriority(job *Job, visited map[*Job]bool) float64 {
+	priority := s.policy.Priority(job)
+	if !s.protocol.Inherits() || len(job.held) == 0 || visited[job] {
+		return priority
+	}
+	visited[job] = true
+	for _, blocked := range s.blockedJobs {
+		if blocked.waitFor != 0 && job.held[blocked.waitFor] > 0 {
+			priority = math.Min(priority, s.inheritedPriority(blocked, visited))
 		}
 	}
+	return priority
+}
 
-	return scheduler, nil
+// preempts reports whether the ready job preempts the running job, taking the
+// inherited priorities into account.
+func (s *simulator) preempts(ready, running *Job) bool {
+	if s.protocol.Inherits() {
+		return s.priority(ready) < s.priority(running)
+	}
+	return s.policy.Preempts(ready, running)
+}
+
+// start dispatches the job on the processor, acquires the resources of the
+// critical sections it is in, and schedules its completion, next critical
+// section boundary and budget exhaustion events. It returns false if the job
+// had to block on a resource instead.
+func (s *simulator) start(job *Job) bool {
+	if !job.started {
+		job.started, job.startTime = true, s.currentTime
+	}
+	if !s.acquireResources(job) {
+		s.block(job)
+		return false
+	}
+
+	s.runningJob = job
+	s.dispatch++
+	s.scheduleRunningJobEvents()
+	return true
+}
+
+// block moves the job to the blocked list until some resource units are released,
+// and checks whether it is deadlocked. Under SRP with consistent preemption levels
+// the admission test makes this unreachable.
+func (s *simulator) block(job *Job) {
+	if s.runningJob == job {
+		s.runningJob = nil
+	}
+	s.blockedJobs = append(s.blockedJobs, job)
+	s.detectDeadlock(job)
+}
+
+// scheduleRunningJobEvents pushes the upcoming events of the running job.
+func (s *simulator) scheduleRunningJobEvents() {
+	job := s.runningJob
+	s.pushEvent(&event{
+		time: s.currentTime + job.RemainingTime, kind: completionEvent,
+		job: job, dispatch: s.dispatch, point: job.ExecTime + job.RemainingTime,
+	})
+
+	if job.Task.Criticality == tasks.HC && s.mode == Normal {
+		if budget := math.Max(job.Task.WCET1-job.ExecTime, 0); budget < job.RemainingTime {
+			s.pushEvent(&event{
+				time: s.currentTime + budget, kind: budgetEvent,
+				job: job, dispatch: s.dispatch, point: job.ExecTime + budget,
+			})
+		}
+	}
+
+	s.scheduleCSBoundary()
+}
+
+// scheduleCSBoundary pushes the next critical section entry or exit of the running job.
+func (s *simulator) scheduleCSBoundary() {
+	job := s.runningJob
+	if boundary, ok := job.nextCSBoundary(); ok && boundary-job.ExecTime < job.RemainingTime {
+		s.pushEvent(&event{
+			time: s.currentTime + boundary - job.ExecTime, kind: csBoundaryEvent,
+			job: job, dispatch: s.dispatch, point: boundary,
+		})
+	}
 }
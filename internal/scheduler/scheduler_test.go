@@ -0,0 +1,283 @@
+package scheduler_test
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+	"github.com/99109766/fms-scheduler/internal/validate"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the tests")
+
+// smallTaskSet returns three independent tasks with implicit deadlines and
+// pairwise distinct deadlines up to time 30.
+func smallTaskSet() []*tasks.Task {
+	return []*tasks.Task{
+		{ID: 1, Criticality: tasks.LC, Period: 5, Deadline: 5, WCET1: 1, PreemptionLevel: 1},
+		{ID: 2, Criticality: tasks.HC, Period: 7, Deadline: 7, WCET1: 2, WCET2: 1, PreemptionLevel: 2},
+		{ID: 3, Criticality: tasks.LC, Period: 11, Deadline: 11, WCET1: 3, PreemptionLevel: 3},
+	}
+}
+
+func TestRunScheduler(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          config.Config
+		schedule     []scheduler.Schedule
+		modeSwitches []scheduler.ModeSwitch
+		suppressed   []scheduler.Suppression
+	}{
+		{
+			// Every job executes its WCET1 under EDF: task 1 preempts task 3 at 5
+			// and 25, and task 2 at 15.
+			name: "edf",
+			cfg:  config.Config{SimulateTime: 30},
+			schedule: []scheduler.Schedule{
+				{TaskID: 1, JobID: 1, StartTime: 0, EndTime: 1},
+				{TaskID: 2, JobID: 2, StartTime: 1, EndTime: 3},
+				{TaskID: 3, JobID: 3, StartTime: 3, EndTime: 5},
+				{TaskID: 1, JobID: 4, StartTime: 5, EndTime: 6},
+				{TaskID: 3, JobID: 3, StartTime: 6, EndTime: 7},
+				{TaskID: 2, JobID: 5, StartTime: 7, EndTime: 9},
+				{TaskID: 1, JobID: 6, StartTime: 10, EndTime: 11},
+				{TaskID: 3, JobID: 7, StartTime: 11, EndTime: 14},
+				{TaskID: 2, JobID: 8, StartTime: 14, EndTime: 15},
+				{TaskID: 1, JobID: 9, StartTime: 15, EndTime: 16},
+				{TaskID: 2, JobID: 8, StartTime: 16, EndTime: 17},
+				{TaskID: 1, JobID: 10, StartTime: 20, EndTime: 21},
+				{TaskID: 2, JobID: 11, StartTime: 21, EndTime: 23},
+				{TaskID: 3, JobID: 12, StartTime: 23, EndTime: 25},
+				{TaskID: 1, JobID: 13, StartTime: 25, EndTime: 26},
+				{TaskID: 3, JobID: 12, StartTime: 26, EndTime: 27},
+				{TaskID: 2, JobID: 14, StartTime: 28, EndTime: 30},
+			},
+		},
+		{
+			// The first job of task 2 overruns its WCET1 at 3: the pending job of
+			// task 3 is dropped and no LC job is released afterwards.
+			name: "overrun",
+			cfg: config.Config{
+				SimulateTime: 15,
+				ExecModel:    config.ExecModelConfig{Kind: scheduler.TableExecution, Table: map[int][]float64{2: {3}}},
+			},
+			schedule: []scheduler.Schedule{
+				{TaskID: 1, JobID: 1, StartTime: 0, EndTime: 1},
+				{TaskID: 2, JobID: 2, StartTime: 1, EndTime: 4},
+				{TaskID: 2, JobID: 4, StartTime: 7, EndTime: 9},
+				{TaskID: 2, JobID: 5, StartTime: 14, EndTime: 15},
+			},
+			modeSwitches: []scheduler.ModeSwitch{
+				{Time: 3, From: scheduler.Normal, To: scheduler.Overrun, Reason: "overrun", TaskID: 2, JobID: 2},
+			},
+			suppressed: []scheduler.Suppression{
+				{Time: 5, TaskID: 1, Mode: scheduler.Overrun},
+				{Time: 10, TaskID: 1, Mode: scheduler.Overrun},
+				{Time: 11, TaskID: 3, Mode: scheduler.Overrun},
+			},
+		},
+		{
+			// The system returns to Normal mode at the idle instant 4.
+			name: "idle return",
+			cfg: config.Config{
+				SimulateTime: 15,
+				ModeReturn:   scheduler.ReturnIdle,
+				ExecModel:    config.ExecModelConfig{Kind: scheduler.TableExecution, Table: map[int][]float64{2: {3}}},
+			},
+			schedule: []scheduler.Schedule{
+				{TaskID: 1, JobID: 1, StartTime: 0, EndTime: 1},
+				{TaskID: 2, JobID: 2, StartTime: 1, EndTime: 4},
+				{TaskID: 1, JobID: 4, StartTime: 5, EndTime: 6},
+				{TaskID: 2, JobID: 5, StartTime: 7, EndTime: 9},
+				{TaskID: 1, JobID: 6, StartTime: 10, EndTime: 11},
+				{TaskID: 3, JobID: 7, StartTime: 11, EndTime: 14},
+				{TaskID: 2, JobID: 8, StartTime: 14, EndTime: 15},
+			},
+			modeSwitches: []scheduler.ModeSwitch{
+				{Time: 3, From: scheduler.Normal, To: scheduler.Overrun, Reason: "overrun", TaskID: 2, JobID: 2},
+				{Time: 4, From: scheduler.Overrun, To: scheduler.Normal, Reason: scheduler.ReturnIdle},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Quiet = true
+			result, err := scheduler.RunScheduler(&cfg, smallTaskSet(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.Schedule, tt.schedule) {
+				t.Errorf("schedule = %v, want %v", result.Schedule, tt.schedule)
+			}
+			if len(result.ModeSwitches) != 0 || len(tt.modeSwitches) != 0 {
+				if !reflect.DeepEqual(result.ModeSwitches, tt.modeSwitches) {
+					t.Errorf("mode switches = %v, want %v", result.ModeSwitches, tt.modeSwitches)
+				}
+			}
+			if !reflect.DeepEqual(result.Suppressed, tt.suppressed) {
+				t.Errorf("suppressed releases = %v, want %v", result.Suppressed, tt.suppressed)
+			}
+		})
+	}
+}
+
+func TestRunSchedulerHyperperiodReturn(t *testing.T) {
+	cfg := &config.Config{
+		SimulateTime: 400,
+		ModeReturn:   scheduler.ReturnHyperperiod,
+		ExecModel:    config.ExecModelConfig{Kind: scheduler.TableExecution, Table: map[int][]float64{2: {3}}},
+		Quiet:        true,
+	}
+	// The hyperperiod of the small task set is 385.
+	result, err := scheduler.RunScheduler(cfg, smallTaskSet(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []scheduler.ModeSwitch{
+		{Time: 3, From: scheduler.Normal, To: scheduler.Overrun, Reason: "overrun", TaskID: 2, JobID: 2},
+		{Time: 388, From: scheduler.Overrun, To: scheduler.Normal, Reason: scheduler.ReturnHyperperiod},
+	}
+	if !reflect.DeepEqual(result.ModeSwitches, want) {
+		t.Errorf("mode switches = %v, want %v", result.ModeSwitches, want)
+	}
+
+	// Periods without a common multiple never return.
+	var taskSet []*tasks.Task
+	for i, period := range []float64{57.123, 113.987, 199.457, 71.111, 93.777} {
+		taskSet = append(taskSet, &tasks.Task{ID: i + 1, Criticality: tasks.LC, Period: period, Deadline: period, WCET1: 1, PreemptionLevel: i + 1})
+	}
+	if _, err := scheduler.RunScheduler(cfg, taskSet, nil); err == nil {
+		t.Error("RunScheduler() accepted a hyperperiod return with an overflowing hyperperiod")
+	}
+}
+
+// simulationConfig returns the configuration of the golden and validated runs:
+// random execution times with frequent overruns on the built-in task set.
+func simulationConfig(algorithm, protocol string, earlyRelease bool, seed int64) *config.Config {
+	return &config.Config{
+		SimulateTime:     3000,
+		Algorithm:        algorithm,
+		Protocol:         protocol,
+		EarlyRelease:     earlyRelease,
+		LCMaxPeriodRatio: 2,
+		ModeReturn:       scheduler.ReturnIdle,
+		MissPolicy:       scheduler.MissSkip,
+		ExecModel: config.ExecModelConfig{
+			Kind:               scheduler.UniformExecution,
+			Range:              [2]float64{0.6, 1},
+			OverrunProbability: 0.3,
+		},
+		Seed:  seed,
+		Quiet: true,
+	}
+}
+
+// golden is the part of a simulation result compared with the golden file.
+type golden struct {
+	Schedule     []scheduler.Schedule   `json:"schedule"`
+	ModeSwitches []scheduler.ModeSwitch `json:"mode_switches"`
+	Misses       []scheduler.Miss       `json:"misses"`
+}
+
+func TestRunSchedulerGolden(t *testing.T) {
+	tests := []struct {
+		algorithm    string
+		earlyRelease bool
+		seed         int64
+	}{
+		{algorithm: tasks.EDF, seed: 1},
+		{algorithm: tasks.EDFVD, earlyRelease: true, seed: 7},
+	}
+
+	for _, tt := range tests {
+		name := fmt.Sprintf("fms_%s_seed%d", tt.algorithm, tt.seed)
+		t.Run(name, func(t *testing.T) {
+			cfg := simulationConfig(tt.algorithm, "", tt.earlyRelease, tt.seed)
+			file, err := tasks.LoadTaskSet(tasks.BuiltinPrefix+"fms", cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := scheduler.RunScheduler(cfg, file.Tasks, file.Resources)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := golden{Schedule: result.Schedule, ModeSwitches: result.ModeSwitches, Misses: result.Misses}
+
+			path := filepath.Join("testdata", name+".json")
+			if *update {
+				data, err := json.MarshalIndent(got, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			var want golden
+			if err := json.Unmarshal(data, &want); err != nil {
+				t.Fatal(err)
+			}
+			compareSchedules(t, got.Schedule, want.Schedule)
+			if !reflect.DeepEqual(got.ModeSwitches, want.ModeSwitches) {
+				t.Errorf("mode switches = %v, want %v", got.ModeSwitches, want.ModeSwitches)
+			}
+			if len(got.Misses) != len(want.Misses) {
+				t.Errorf("%d deadline misses, want %d", len(got.Misses), len(want.Misses))
+			}
+		})
+	}
+}
+
+// compareSchedules reports the first execution interval differing from the
+// golden schedule beyond rounding errors.
+func compareSchedules(t *testing.T, got, want []scheduler.Schedule) {
+	t.Helper()
+	for i := 0; i < len(got) && i < len(want); i++ {
+		g, w := got[i], want[i]
+		if g.TaskID != w.TaskID || g.JobID != w.JobID || math.Abs(g.StartTime-w.StartTime) > 1e-9 || math.Abs(g.EndTime-w.EndTime) > 1e-9 {
+			t.Fatalf("interval %d = %+v, want %+v", i, g, w)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%d intervals, want %d", len(got), len(want))
+	}
+}
+
+func TestRunSchedulerValidates(t *testing.T) {
+	for _, algorithm := range []string{tasks.EDF, tasks.EDFVD, tasks.DM, tasks.RM, tasks.AMC} {
+		for _, protocol := range scheduler.Protocols {
+			for _, earlyRelease := range []bool{false, true} {
+				name := fmt.Sprintf("%s/%s/early_release=%v", algorithm, protocol, earlyRelease)
+				t.Run(name, func(t *testing.T) {
+					cfg := simulationConfig(algorithm, protocol, earlyRelease, 3)
+					file, err := tasks.LoadTaskSet(tasks.BuiltinPrefix+"fms", cfg)
+					if err != nil {
+						t.Fatal(err)
+					}
+					result, err := scheduler.RunScheduler(cfg, file.Tasks, file.Resources)
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, v := range validate.Validate(result, file.Tasks, file.Resources, validate.WithConfig(cfg)) {
+						t.Error(v)
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package tasks
+
+import (
+	_ "embed"
+	"fmt"
+)
+
+// fmsTaskSet is the reference flight management system task set. Times are in
+// milliseconds.
+//
+//go:embed fms.json
+var fmsTaskSet []byte
+
+// builtinTaskSet returns the task set file bundled under the given name.
+func builtinTaskSet(name string) ([]byte, error) {
+	switch name {
+	case "fms":
+		return fmsTaskSet, nil
+	default:
+		return nil, fmt.Errorf("unknown built-in task set %q", name)
+	}
+}
@@ -0,0 +1,196 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/resources"
+)
+
+// TaskSetVersion is the version of the task set file format written by SaveTaskSet.
+const TaskSetVersion = 1
+
+// BuiltinPrefix marks the name of a task set bundled with the program, as in
+// "builtin:fms", wherever a task set file path is expected.
+const BuiltinPrefix = "builtin:"
+
+// TaskSetFile is the on-disk form of a task set together with its resources.
+// Resource assignments, priorities, preemption levels, ceilings and blocking
+// times may be left out: the loader derives them from the critical sections.
+type TaskSetFile struct {
+	Version int    `json:"version"`
+	Name    string `json:"name,omitempty"`
+
+	// Seed is the seed the task set was generated with, if it was generated.
+	Seed int64 `json:"seed,omitempty"`
+
+	Tasks     []*Task               `json:"tasks"`
+	Resources []*resources.Resource `json:"resources"`
+}
+
+// SaveTaskSet writes the task set generated with the seed and its resources to a JSON file.
+func SaveTaskSet(filePath string, seed int64, taskSet []*Task, resourceList []*resources.Resource) error {
+	file := TaskSetFile{Version: TaskSetVersion, Seed: seed, Tasks: taskSet, Resources: resourceList}
+	encoded, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, encoded, 0o644)
+}
+
+// LoadTaskSet reads and validates a task set file, or the built-in task set named
+// after BuiltinPrefix. Priorities missing from the file are assigned rate
+// monotonically. With a configuration, the preemption levels are assigned as it
+// selects, replacing the ones stored in the file, as generated task sets get
+// theirs. Without one, the stored levels are kept and missing ones get the
+// defaults. Resource ceilings and blocking times are always recomputed.
+func LoadTaskSet(filePath string, cfg *config.Config) (*TaskSetFile, error) {
+	var data []byte
+	var err error
+	if name, ok := strings.CutPrefix(filePath, BuiltinPrefix); ok {
+		data, err = builtinTaskSet(name)
+	} else {
+		data, err = os.ReadFile(filePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file TaskSetFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	if err := file.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: invalid task set:\n%w", filePath, err)
+	}
+
+	file.derive(cfg)
+	return &file, nil
+}
+
+// Validate checks that the file describes a consistent task set: a supported
+// version, unique positive IDs, positive timing parameters, no WCET2 for LC
+// tasks, and properly nested critical sections within WCET1 on known resources
+// with enough units. All problems found are reported together.
+func (f *TaskSetFile) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if f.Version != TaskSetVersion {
+		fail("unsupported version %d, expected %d", f.Version, TaskSetVersion)
+	}
+	if len(f.Tasks) == 0 {
+		fail("no tasks")
+	}
+
+	units := make(map[int]int)
+	for _, r := range f.Resources {
+		if r.ID <= 0 {
+			fail("resource %d: ID must be positive", r.ID)
+		}
+		if _, ok := units[r.ID]; ok {
+			fail("resource %d: duplicate ID", r.ID)
+		}
+		if r.Units < 1 {
+			fail("resource %d: must have at least one unit", r.ID)
+		}
+		units[r.ID] = r.Units
+	}
+
+	ids := make(map[int]bool)
+	for _, t := range f.Tasks {
+		if t.ID <= 0 {
+			fail("task %d: ID must be positive", t.ID)
+		}
+		if ids[t.ID] {
+			fail("task %d: duplicate ID", t.ID)
+		}
+		ids[t.ID] = true
+
+		if t.Period <= 0 || t.Deadline <= 0 || t.WCET1 <= 0 {
+			fail("task %d: period, deadline and WCET1 must be positive", t.ID)
+		}
+		if t.WCET2 < 0 || (t.Criticality == LC && t.WCET2 != 0) {
+			fail("task %d: WCET2 must be non-negative, and zero for LC tasks", t.ID)
+		}
+
+		for _, cs := range t.CriticalSections {
+			available, ok := units[cs.ResourceID]
+			if !ok {
+				fail("task %d: critical section on unknown resource %d", t.ID, cs.ResourceID)
+			} else if cs.Units < 1 || cs.Units > available {
+				fail("task %d: critical section requests %d of the %d units of resource %d", t.ID, cs.Units, available, cs.ResourceID)
+			}
+			if cs.Start < 0 || cs.Duration <= 0 || cs.End() > t.WCET1 {
+				fail("task %d: critical section on resource %d must lie within WCET1", t.ID, cs.ResourceID)
+			}
+		}
+		if err := CheckNesting(t.CriticalSections); err != nil {
+			fail("task %d: %w", t.ID, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// derive sorts the critical sections of every task in acquisition order and
+// fills in the data the pipeline derives from them: resource assignments,
+// priorities, preemption levels, resource ceilings and blocking times. The
+// preemption levels are recomputed unless cfg is nil and the file has them all.
+func (f *TaskSetFile) derive(cfg *config.Config) {
+	users := make(map[int][]int)
+	for _, t := range f.Tasks {
+		SortCriticalSections(t.CriticalSections)
+		used := make(map[int]bool)
+		for _, cs := range t.CriticalSections {
+			if !used[cs.ResourceID] {
+				used[cs.ResourceID] = true
+				users[cs.ResourceID] = append(users[cs.ResourceID], t.ID)
+			}
+		}
+		if len(t.AssignedResIDs) == 0 {
+			t.AssignedResIDs = make([]int, 0, len(used))
+			for id := range used {
+				t.AssignedResIDs = append(t.AssignedResIDs, id)
+			}
+			sort.Ints(t.AssignedResIDs)
+		}
+	}
+	for _, r := range f.Resources {
+		if len(r.AssignedTasks) == 0 {
+			r.AssignedTasks = append(make([]int, 0), users[r.ID]...)
+			sort.Ints(r.AssignedTasks)
+		}
+	}
+
+	// Priorities and preemption levels start at 1, so zero means left out.
+	if !allTasks(f.Tasks, func(t *Task) bool { return t.Priority > 0 }) {
+		DeterminePriorityLevels(f.Tasks)
+	}
+	switch {
+	case cfg != nil:
+		ComputePreemptionLevels(cfg, f.Tasks, f.Resources)
+	case allTasks(f.Tasks, func(t *Task) bool { return t.PreemptionLevel > 0 }):
+		ComputeResourceCeilings(f.Tasks, f.Resources)
+	default:
+		ComputePreemptionLevels(&config.Config{}, f.Tasks, f.Resources)
+	}
+	ComputeBlockingTimes(f.Tasks, f.Resources)
+}
+
+// allTasks reports whether the predicate holds for every task.
+func allTasks(taskSet []*Task, predicate func(*Task) bool) bool {
+	for _, t := range taskSet {
+		if !predicate(t) {
+			return false
+		}
+	}
+	return true
+}
@@ -6,40 +6,56 @@ import (
 	"sort"
 
 	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/resources"
 )
 
+// GenerateTaskSet runs the whole generation pipeline: it generates tasks with
+// UUnifast and resources, assigns resources and critical sections to the tasks,
+// and computes priorities, preemption levels, resource ceilings and blocking times.
+// All random draws come from rng, so the same seed yields the same task set.
+func GenerateTaskSet(cfg *config.Config, rng *rand.Rand) ([]*Task, []*resources.Resource) {
+	taskSet := GenerateTasksUUnifast(cfg, rng)
+	resourceList := resources.GenerateResources(cfg, rng)
+	AssignResourcesToTasks(cfg, rng, taskSet, resourceList)
+	AssignCriticalSections(cfg, rng, taskSet, resourceList)
+	DeterminePriorityLevels(taskSet)
+	ComputePreemptionLevels(cfg, taskSet, resourceList)
+	ComputeBlockingTimes(taskSet, resourceList)
+	return taskSet, resourceList
+}
+
 // GenerateTasksUUnifast generates a set of tasks whose sum of utilization = totalUtil.
-func GenerateTasksUUnifast(cfg *config.Config) []*Task {
+func GenerateTasksUUnifast(cfg *config.Config, rng *rand.Rand) []*Task {
 	numTasks, totalUtil := cfg.NumTasks, cfg.TotalUtility
 
 	// Apply UUnifast algorithm
-	utilizations := uUniFast(numTasks, totalUtil)
+	utilizations := uUniFast(rng, numTasks, totalUtil)
 
 	// Create Task structures
 	tasks := make([]*Task, numTasks)
 	for i := 0; i < numTasks; i++ {
-		period := cfg.PeriodRange[0] + rand.Float64()*(cfg.PeriodRange[1]-cfg.PeriodRange[0])
+		period := cfg.PeriodRange[0] + rng.Float64()*(cfg.PeriodRange[1]-cfg.PeriodRange[0])
 		wcet := utilizations[i] * period
 
 		tasks[i] = &Task{
 			ID:       i + 1,
 			WCET1:    wcet,
 			Period:   period,
-			Deadline: period * (cfg.DeadlineRatio[0] + rand.Float64()*(cfg.DeadlineRatio[1]-cfg.DeadlineRatio[0])),
+			Deadline: period * (cfg.DeadlineRatio[0] + rng.Float64()*(cfg.DeadlineRatio[1]-cfg.DeadlineRatio[0])),
 		}
 	}
 
-	assignRandomCriticality(cfg, tasks)
+	assignRandomCriticality(cfg, rng, tasks)
 
 	return tasks
 }
 
 // assignRandomCriticality assigns random criticality to tasks.
-func assignRandomCriticality(cfg *config.Config, tasks []*Task) {
+func assignRandomCriticality(cfg *config.Config, rng *rand.Rand, tasks []*Task) {
 	for _, t := range tasks {
-		if rand.Float64() < cfg.HighRatio {
+		if rng.Float64() < cfg.HighRatio {
 			t.Criticality = HC
-			t.WCET2 = (cfg.WCETRatio[0] + rand.Float64()*(cfg.WCETRatio[1]-cfg.WCETRatio[0])) * t.WCET1
+			t.WCET2 = (cfg.WCETRatio[0] + rng.Float64()*(cfg.WCETRatio[1]-cfg.WCETRatio[0])) * t.WCET1
 		} else {
 			t.Criticality = LC
 			t.WCET2 = 0
@@ -48,11 +64,11 @@ func assignRandomCriticality(cfg *config.Config, tasks []*Task) {
 }
 
 // uUniFast is the internal function implementing the UUniFast algorithm.
-func uUniFast(n int, U float64) []float64 {
+func uUniFast(rng *rand.Rand, n int, U float64) []float64 {
 	sumU := U
 	utils := make([]float64, n)
 	for i := 1; i < n; i++ {
-		next := sumU * (math.Pow(rand.Float64(), 1.0/float64(n-i)))
+		next := sumU * (math.Pow(rng.Float64(), 1.0/float64(n-i)))
 		utils[i-1] = sumU - next
 		sumU = next
 	}
@@ -62,11 +78,11 @@ func uUniFast(n int, U float64) []float64 {
 
 // randomArray generates a random array of n elements whose sum is sum.
 // The minimum value of each element is 1.
-func randomArray(n, sum int) []int {
+func randomArray(rng *rand.Rand, n, sum int) []int {
 	sum -= n
 	arr := make([]int, n)
 	for i := 0; i < n-1; i++ {
-		arr[i] = rand.Intn(sum + 1)
+		arr[i] = rng.Intn(sum + 1)
 	}
 	arr[n-1] = sum
 	sort.Ints(arr)
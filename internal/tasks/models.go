@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"encoding/json"
 	"fmt"
 )
 
@@ -11,8 +12,56 @@ const (
 	HC
 )
 
+func (c CriticalityLevel) String() string {
+	if c == HC {
+		return "HC"
+	}
+	return "LC"
+}
+
+func (c CriticalityLevel) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText accepts "LC" and "HC", and the numeric form of task set files
+// written before criticality levels were named: 0 for LC and 1 for HC.
+func (c *CriticalityLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "LC", "0":
+		*c = LC
+	case "HC", "1":
+		*c = HC
+	default:
+		return fmt.Errorf("unknown criticality %q", text)
+	}
+	return nil
+}
+
+// UnmarshalJSON accepts the criticality as a string or as a legacy number.
+func (c *CriticalityLevel) UnmarshalJSON(data []byte) error {
+	return UnmarshalTextOrNumber(data, c.UnmarshalText)
+}
+
+// UnmarshalTextOrNumber decodes an enumeration written as a JSON string or, in
+// older files, as a JSON number: the string, unquoted, or the number, as is, is
+// passed to the text unmarshaler. Null leaves the value unchanged.
+func UnmarshalTextOrNumber(data []byte, unmarshalText func([]byte) error) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		data = []byte(text)
+	}
+	return unmarshalText(data)
+}
+
 type CriticalSection struct {
 	ResourceID int     `json:"resource_id"`
+	Units      int     `json:"units"`
 	Start      float64 `json:"start"`
 	Duration   float64 `json:"duration"`
 }
@@ -23,6 +72,7 @@ func (cs CriticalSection) End() float64 {
 
 type Task struct {
 	ID               int                `json:"id"`
+	Name             string             `json:"name,omitempty"`
 	Criticality      CriticalityLevel   `json:"criticality"`
 	Period           float64            `json:"period"`
 	Deadline         float64            `json:"deadline"`
@@ -30,8 +80,22 @@ type Task struct {
 	WCET2            float64            `json:"wcet2"`
 	AssignedResIDs   []int              `json:"assigned_res_ids"`
 	CriticalSections []*CriticalSection `json:"critical_sections"`
-	Priority         int                `json:"-"`
-	PreemptionLevel  int                `json:"-"`
+	Blocking         float64            `json:"blocking"`
+	Priority         int                `json:"priority"`
+	PreemptionLevel  int                `json:"preemption_level"`
+}
+
+// ResourceDemand returns the maximum number of units of the resource the task
+// holds at once. A critical section nested in another one on the same resource
+// re-acquires the resource, so only the larger request counts.
+func (t *Task) ResourceDemand(resourceID int) int {
+	demand := 0
+	for _, cs := range t.CriticalSections {
+		if cs.ResourceID == resourceID && cs.Units > demand {
+			demand = cs.Units
+		}
+	}
+	return demand
 }
 
 func (t *Task) Utilization() float64 {
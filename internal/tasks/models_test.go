@@ -0,0 +1,41 @@
+package tasks
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCriticalityLevelJSON(t *testing.T) {
+	tests := []struct {
+		data  string
+		level CriticalityLevel
+		valid bool
+	}{
+		{data: `"LC"`, level: LC, valid: true},
+		{data: `"HC"`, level: HC, valid: true},
+		{data: `0`, level: LC, valid: true},
+		{data: `1`, level: HC, valid: true},
+		{data: `"1"`, level: HC, valid: true},
+		{data: `2`},
+		{data: `"MC"`},
+		{data: `true`},
+		{data: `null`, level: LC, valid: true},
+	}
+
+	for _, tt := range tests {
+		var level CriticalityLevel
+		err := json.Unmarshal([]byte(tt.data), &level)
+		if (err == nil) != tt.valid {
+			t.Errorf("Unmarshal(%s) error = %v, want valid %v", tt.data, err, tt.valid)
+			continue
+		}
+		if tt.valid && level != tt.level {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.data, level, tt.level)
+		}
+	}
+
+	data, err := json.Marshal(struct{ Criticality CriticalityLevel }{HC})
+	if err != nil || string(data) != `{"Criticality":"HC"}` {
+		t.Errorf("Marshal(HC) = %s, %v", data, err)
+	}
+}
@@ -0,0 +1,128 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// nestingTolerance absorbs the rounding errors of the critical section bounds
+// when comparing them.
+const nestingTolerance = 1e-9
+
+// LockOp is one step of the acquire/release sequence of a task: at the point At
+// of its execution, the task acquires or releases the units of a critical section.
+type LockOp struct {
+	At         float64
+	Acquire    bool
+	ResourceID int
+	Units      int
+
+	// Depth is the nesting depth of the critical section, 1 for an outermost one.
+	Depth int
+}
+
+func (op LockOp) String() string {
+	verb := "release"
+	if op.Acquire {
+		verb = "acquire"
+	}
+	return fmt.Sprintf("%.2f: %s %d unit(s) of resource %d", op.At, verb, op.Units, op.ResourceID)
+}
+
+// Encloses reports whether the critical section other is nested in cs.
+func (cs CriticalSection) Encloses(other *CriticalSection) bool {
+	return cs.Start <= other.Start+nestingTolerance && other.End() <= cs.End()+nestingTolerance
+}
+
+// SortCriticalSections sorts the critical sections in acquisition order: by
+// start, and a section before the ones nested in it when they start together.
+func SortCriticalSections(sections []*CriticalSection) {
+	sort.SliceStable(sections, func(i, j int) bool {
+		if sections[i].Start != sections[j].Start {
+			return sections[i].Start < sections[j].Start
+		}
+		return sections[i].Duration > sections[j].Duration
+	})
+}
+
+// CheckNesting checks that the critical sections are properly nested: any two of
+// them are either disjoint or one encloses the other. Every partial overlap is
+// reported.
+func CheckNesting(sections []*CriticalSection) error {
+	sorted := append([]*CriticalSection(nil), sections...)
+	SortCriticalSections(sorted)
+
+	var errs []error
+	for i, outer := range sorted {
+		for _, inner := range sorted[i+1:] {
+			if inner.Start >= outer.End()-nestingTolerance {
+				break
+			}
+			if !outer.Encloses(inner) {
+				errs = append(errs, fmt.Errorf("critical section on resource %d [%g, %g] partially overlaps the one on resource %d [%g, %g]",
+					outer.ResourceID, outer.Start, outer.End(), inner.ResourceID, inner.Start, inner.End()))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Enclosing returns the critical sections of the task enclosing its i-th one,
+// outermost first. The critical sections must be properly nested and sorted by
+// SortCriticalSections.
+func (t *Task) Enclosing(i int) []*CriticalSection {
+	var chain []*CriticalSection
+	for _, cs := range t.CriticalSections[:i] {
+		if cs.Encloses(t.CriticalSections[i]) {
+			chain = append(chain, cs)
+		}
+	}
+	return chain
+}
+
+// HeldUnits returns the number of units of the resource of the i-th critical
+// section the task holds inside it. A section nested in another one on the same
+// resource re-acquires the resource, so the largest request counts.
+func (t *Task) HeldUnits(i int) int {
+	cs := t.CriticalSections[i]
+	units := cs.Units
+	for _, outer := range t.Enclosing(i) {
+		if outer.ResourceID == cs.ResourceID && outer.Units > units {
+			units = outer.Units
+		}
+	}
+	return units
+}
+
+// LockSequence returns the critical sections of the task as a sequence of
+// acquisitions and releases in execution order. A section is acquired before and
+// released after the ones nested in it, and releases come first at the same
+// point. The critical sections must be properly nested and sorted by
+// SortCriticalSections.
+func (t *Task) LockSequence() []LockOp {
+	ops := make([]LockOp, 0, 2*len(t.CriticalSections))
+	var open []*CriticalSection
+
+	// releaseUntil releases the open sections ending at or before the point,
+	// innermost first.
+	releaseUntil := func(point float64) {
+		for len(open) > 0 {
+			cs := open[len(open)-1]
+			if cs.End() > point+nestingTolerance {
+				return
+			}
+			ops = append(ops, LockOp{At: cs.End(), ResourceID: cs.ResourceID, Units: cs.Units, Depth: len(open)})
+			open = open[:len(open)-1]
+		}
+	}
+
+	for _, cs := range t.CriticalSections {
+		releaseUntil(cs.Start)
+		open = append(open, cs)
+		ops = append(ops, LockOp{At: cs.Start, Acquire: true, ResourceID: cs.ResourceID, Units: cs.Units, Depth: len(open)})
+	}
+	releaseUntil(math.Inf(1))
+	return ops
+}
@@ -0,0 +1,131 @@
+package tasks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckNesting(t *testing.T) {
+	tests := []struct {
+		name     string
+		sections []*CriticalSection
+		valid    bool
+	}{
+		{
+			name:     "disjoint",
+			sections: []*CriticalSection{section(1, 1, 0, 1), section(2, 1, 2, 1)},
+			valid:    true,
+		},
+		{
+			name:     "back to back",
+			sections: []*CriticalSection{section(1, 1, 0, 1), section(2, 1, 1, 1)},
+			valid:    true,
+		},
+		{
+			name:     "nested, listed innermost first",
+			sections: []*CriticalSection{section(2, 1, 1, 1), section(1, 1, 0, 3)},
+			valid:    true,
+		},
+		{
+			name:     "nested with a common start",
+			sections: []*CriticalSection{section(1, 1, 0, 3), section(2, 1, 0, 1)},
+			valid:    true,
+		},
+		{
+			name:     "partial overlap",
+			sections: []*CriticalSection{section(1, 1, 0, 2), section(2, 1, 1, 2)},
+			valid:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckNesting(tt.sections)
+			if (err == nil) != tt.valid {
+				t.Errorf("CheckNesting() = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestLockSequence(t *testing.T) {
+	acquire := func(at float64, resourceID, units, depth int) LockOp {
+		return LockOp{At: at, Acquire: true, ResourceID: resourceID, Units: units, Depth: depth}
+	}
+	release := func(at float64, resourceID, units, depth int) LockOp {
+		return LockOp{At: at, ResourceID: resourceID, Units: units, Depth: depth}
+	}
+
+	tests := []struct {
+		name     string
+		sections []*CriticalSection
+		ops      []LockOp
+	}{
+		{
+			name:     "releases come first at the same point",
+			sections: []*CriticalSection{section(1, 1, 0, 2), section(2, 2, 2, 1)},
+			ops: []LockOp{
+				acquire(0, 1, 1, 1), release(2, 1, 1, 1),
+				acquire(2, 2, 2, 1), release(3, 2, 2, 1),
+			},
+		},
+		{
+			name:     "nested sections",
+			sections: []*CriticalSection{section(2, 1, 1, 2), section(1, 1, 0, 4), section(3, 1, 5, 1)},
+			ops: []LockOp{
+				acquire(0, 1, 1, 1), acquire(1, 2, 1, 2), release(3, 2, 1, 2), release(4, 1, 1, 1),
+				acquire(5, 3, 1, 1), release(6, 3, 1, 1),
+			},
+		},
+		{
+			name:     "common end releases the inner section first",
+			sections: []*CriticalSection{section(1, 1, 0, 3), section(2, 1, 1, 2)},
+			ops: []LockOp{
+				acquire(0, 1, 1, 1), acquire(1, 2, 1, 2), release(3, 2, 1, 2), release(3, 1, 1, 1),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{CriticalSections: tt.sections}
+			SortCriticalSections(task.CriticalSections)
+			if got := task.LockSequence(); !reflect.DeepEqual(got, tt.ops) {
+				t.Errorf("LockSequence() = %v, want %v", got, tt.ops)
+			}
+		})
+	}
+}
+
+func TestEnclosingAndHeldUnits(t *testing.T) {
+	task := &Task{CriticalSections: []*CriticalSection{
+		section(1, 1, 0, 6),
+		section(2, 1, 1, 4),
+		section(1, 2, 2, 1),
+		section(3, 1, 7, 1),
+	}}
+
+	tests := []struct {
+		index     int
+		enclosing []int
+		held      int
+	}{
+		{index: 0, enclosing: nil, held: 1},
+		{index: 1, enclosing: []int{1}, held: 1},
+		{index: 2, enclosing: []int{1, 2}, held: 2},
+		{index: 3, enclosing: nil, held: 1},
+	}
+
+	for _, tt := range tests {
+		var enclosing []int
+		for _, cs := range task.Enclosing(tt.index) {
+			enclosing = append(enclosing, cs.ResourceID)
+		}
+		if !reflect.DeepEqual(enclosing, tt.enclosing) {
+			t.Errorf("Enclosing(%d) = %v, want %v", tt.index, enclosing, tt.enclosing)
+		}
+		if got := task.HeldUnits(tt.index); got != tt.held {
+			t.Errorf("HeldUnits(%d) = %d, want %d", tt.index, got, tt.held)
+		}
+	}
+}
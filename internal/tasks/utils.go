@@ -10,14 +10,14 @@ import (
 )
 
 // AssignResourcesToTasks randomly assigns resources to tasks.
-func AssignResourcesToTasks(cfg *config.Config, tasks []*Task, resources []*resources.Resource) {
+func AssignResourcesToTasks(cfg *config.Config, rng *rand.Rand, tasks []*Task, resources []*resources.Resource) {
 	for _, r := range resources {
 		r.AssignedTasks = nil
 	}
 
 	for _, t := range tasks {
-		resourceIndexes := rand.Perm(len(resources))
-		numResources := cfg.ResourceUsage[0] + rand.Intn(cfg.ResourceUsage[1]-cfg.ResourceUsage[0]+1)
+		resourceIndexes := rng.Perm(len(resources))
+		numResources := cfg.ResourceUsage[0] + rng.Intn(cfg.ResourceUsage[1]-cfg.ResourceUsage[0]+1)
 		for i := 0; i < numResources; i++ {
 			r := resources[resourceIndexes[i]]
 			t.AssignedResIDs = append(t.AssignedResIDs, r.ID)
@@ -27,8 +27,17 @@ func AssignResourcesToTasks(cfg *config.Config, tasks []*Task, resources []*reso
 }
 
 // AssignCriticalSections simulates that each assigned resource has a critical section in the task.
-// The critical sections are assigned start times and durations so that they do not partially overlap.
-func AssignCriticalSections(cfg *config.Config, tasks []*Task, resources []*resources.Resource) {
+// The critical sections are placed in groups separated by gaps. Within a group each section is
+// nested in the previous one, so that the sections are properly nested and sorted in acquisition
+// order. Each critical section requests a random number of units of its resource, bounded by the
+// resource's capacity.
+func AssignCriticalSections(cfg *config.Config, rng *rand.Rand, tasks []*Task, resources []*resources.Resource) {
+	// Build a map for quick resource lookup by ID.
+	resourceMap := make(map[int]int)
+	for _, r := range resources {
+		resourceMap[r.ID] = r.Units
+	}
+
 	for _, t := range tasks {
 		t.CriticalSections = nil
 		if len(t.AssignedResIDs) == 0 {
@@ -36,60 +45,66 @@ func AssignCriticalSections(cfg *config.Config, tasks []*Task, resources []*reso
 		}
 
 		// Total critical section duration (a fraction of WCET1)
-		totalDuration := t.WCET1 * rand.Float64() * cfg.CSFactor
+		totalDuration := t.WCET1 * rng.Float64() * cfg.CSFactor
 
 		// Determine the number of critical sections to place in the task.
-		numSections := cfg.CSRange[0] + rand.Intn(cfg.CSRange[1]-cfg.CSRange[0]+1)
+		numSections := cfg.CSRange[0] + rng.Intn(cfg.CSRange[1]-cfg.CSRange[0]+1)
 		if numSections == 0 {
 			numSections = 1
 		}
 
 		// Split totalDuration among the sections using uUniFast
-		durations := uUniFast(numSections, totalDuration)
+		durations := uUniFast(rng, numSections, totalDuration)
 
 		// Compute available free time in the task (WCET1 minus total CS duration)
 		freeTime := math.Max(t.WCET1-totalDuration, 0)
 
 		// Distribute free time as gaps before, between, and after critical sections.
-		gaps := uUniFast(numSections+1, freeTime)
+		gaps := uUniFast(rng, numSections+1, freeTime)
 
 		// Randomly shuffle the number of resources to assign to each critical section.
 		var numResources []int
 		if numSections <= len(t.AssignedResIDs) {
-			numResources = randomArray(numSections, len(t.AssignedResIDs))
+			numResources = randomArray(rng, numSections, len(t.AssignedResIDs))
 		} else {
-			numResources = randomArray(rand.Intn(len(t.AssignedResIDs))+1, len(t.AssignedResIDs))
+			numResources = randomArray(rng, rng.Intn(len(t.AssignedResIDs))+1, len(t.AssignedResIDs))
 			for len(numResources) < numSections {
 				numResources = append(numResources, 1)
 			}
 		}
 
-		// Place critical sections sequentially.
-		currentTime, currentTaskIndex := gaps[0], 0
+		// Place the groups of nested critical sections sequentially.
+		groupStart, currentTaskIndex := gaps[0], 0
 		for i, numResource := range numResources {
-			// Split the duration of the critical section among the assigned resources.
-			resourceDurations := uUniFast(numResource, durations[i])
+			// Split the duration of the group among the nested sections: each one
+			// starts and ends within the previous one.
+			resourceDurations := uUniFast(rng, numResource, durations[i])
+			groupEnd := groupStart + durations[i]
 
-			// Place the critical section.
-			leftDuration := durations[i]
+			currentTime, leftDuration := groupStart, durations[i]
 			for j := 0; j < numResource; j++ {
+				resourceID := t.AssignedResIDs[currentTaskIndex%len(t.AssignedResIDs)]
+				units := cfg.CSUnits[0] + rng.Intn(cfg.CSUnits[1]-cfg.CSUnits[0]+1)
+				if capacity := resourceMap[resourceID]; units > capacity {
+					units = capacity
+				}
+
 				t.CriticalSections = append(t.CriticalSections, &CriticalSection{
-					ResourceID: t.AssignedResIDs[currentTaskIndex%len(t.AssignedResIDs)],
+					ResourceID: resourceID,
+					Units:      units,
 					Start:      currentTime,
-					Duration:   leftDuration,
+					Duration:   math.Min(leftDuration, groupEnd-currentTime),
 				})
 
 				if j < numResource-1 {
-					currentTime += resourceDurations[j] * rand.Float64()
-				} else {
-					currentTime += resourceDurations[j]
+					currentTime += resourceDurations[j] * rng.Float64()
 				}
 				leftDuration -= resourceDurations[j]
 				currentTaskIndex++
 			}
 
-			// Add gap after the critical section.
-			currentTime += gaps[i+1]
+			// The next group starts after the outermost section and a gap.
+			groupStart = groupEnd + gaps[i+1]
 		}
 	}
 }
@@ -106,48 +121,197 @@ func DeterminePriorityLevels(taskSet []*Task) {
 	}
 }
 
-// ComputeResourceCeilings computes and sets the ceiling for each resource.
-// The ceiling is defined as the highest priority (i.e. lowest numerical value)
-// among the tasks that are assigned to the resource.
-func ComputeResourceCeilings(taskSet []*Task, resourceList []*resources.Resource) {
-	// Build a map for quick task lookup by ID.
-	taskMap := make(map[int]*Task)
+// Preemption level assignments selectable with the preemption_levels config field.
+const (
+	DeadlinePreemptionLevels = "deadline"
+	RMPreemptionLevels       = "rm"
+)
+
+// ComputePreemptionLevels assigns the preemption levels selected by the configuration
+// and then computes the resource ceilings from them. By default the levels follow
+// the priorities of the scheduling algorithm: rate-monotonic under RM and AMC,
+// deadline based otherwise. With rate-monotonic levels, DeterminePriorityLevels
+// must have been called first. Under EDF-VD the deadline based levels follow the
+// virtual deadlines of HC tasks.
+func ComputePreemptionLevels(cfg *config.Config, taskSet []*Task, resourceList []*resources.Resource) {
+	rmDefault := cfg.PreemptionLevels == "" && (cfg.Algorithm == RM || cfg.Algorithm == AMC)
+	switch {
+	case cfg.PreemptionLevels == RMPreemptionLevels || rmDefault:
+		AssignPreemptionLevels(taskSet)
+	case cfg.Algorithm == EDFVD:
+		x, _ := VirtualDeadlineFactor(taskSet)
+		assignDeadlineLevels(taskSet, func(t *Task) float64 {
+			if t.Criticality == HC {
+				return x * t.Deadline
+			}
+			return t.Deadline
+		})
+	default:
+		AssignDeadlinePreemptionLevels(taskSet)
+	}
+	ComputeResourceCeilings(taskSet, resourceList)
+}
+
+// AssignPreemptionLevels assigns each task a preemption level.
+// The preemption level is the task's static (rate-monotonic) priority rank, which is
+// only consistent with EDF when deadlines are implicit.
+func AssignPreemptionLevels(taskSet []*Task) {
 	for _, t := range taskSet {
-		taskMap[t.ID] = t
+		t.PreemptionLevel = t.Priority
+	}
+}
+
+// AssignDeadlinePreemptionLevels assigns preemption levels inversely proportional to
+// the relative deadlines (Baker's definition for EDF): the task with the shortest
+// relative deadline gets level 1, and tasks with equal deadlines share a level.
+// Unlike DeterminePriorityLevels, the order of the task set is left untouched.
+func AssignDeadlinePreemptionLevels(taskSet []*Task) {
+	assignDeadlineLevels(taskSet, func(t *Task) float64 { return t.Deadline })
+}
+
+// assignDeadlineLevels ranks the tasks by the given relative deadline.
+func assignDeadlineLevels(taskSet []*Task, deadline func(*Task) float64) {
+	sorted := make([]*Task, len(taskSet))
+	copy(sorted, taskSet)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return deadline(sorted[i]) < deadline(sorted[j])
+	})
+
+	level := 0
+	for i, t := range sorted {
+		if i == 0 || deadline(t) > deadline(sorted[i-1]) {
+			level++
+		}
+		t.PreemptionLevel = level
 	}
+}
 
+// ComputeResourceCeilings computes and sets the ceilings of each resource.
+// For a multi-unit resource R the ceiling depends on the number of available units n:
+// it is the highest preemption level (i.e. lowest numerical value) among the tasks
+// that may request more than n units of R. The static ceiling is the ceiling when no
+// unit is available. Resources without any user keep math.MaxInt32 as ceiling.
+func ComputeResourceCeilings(taskSet []*Task, resourceList []*resources.Resource) {
 	for _, r := range resourceList {
-		ceiling := math.MaxInt32
-		for _, taskID := range r.AssignedTasks {
-			if t, ok := taskMap[taskID]; ok {
-				if t.Priority < ceiling {
-					ceiling = t.Priority
+		r.Ceilings = make([]int, r.Units+1)
+		for available := range r.Ceilings {
+			r.Ceilings[available] = math.MaxInt32
+		}
+
+		for _, t := range taskSet {
+			demand := t.ResourceDemand(r.ID)
+			for available := 0; available < demand && available <= r.Units; available++ {
+				if t.PreemptionLevel < r.Ceilings[available] {
+					r.Ceilings[available] = t.PreemptionLevel
 				}
 			}
 		}
-		r.Ceiling = ceiling
+		r.Ceiling = r.Ceilings[0]
 	}
 }
 
-// AssignPreemptionLevels assigns each task a preemption level.
-// For a task, the preemption level is defined as the minimum of its base priority
-// and the ceilings of all resources it uses.
-func AssignPreemptionLevels(taskSet []*Task, resourceList []*resources.Resource) {
-	// Build a map for quick resource lookup by ID.
+// ComputeBlockingTimes computes and sets the maximum SRP blocking time of each task.
+// Preemption levels and resource ceilings must have been computed beforehand.
+func ComputeBlockingTimes(taskSet []*Task, resourceList []*resources.Resource) {
+	for _, t := range taskSet {
+		t.Blocking = BlockingTime(t, taskSet, resourceList)
+	}
+}
+
+// BlockingTime returns the maximum time the task can be blocked under SRP by the
+// given other tasks: the longest critical section of a task with a lower preemption
+// level on a resource whose ceiling, with the section's units taken, is at least
+// the preemption level of the task. The duration of a critical section includes
+// the sections nested in it, so a job blocked by an outer section waits for all
+// of them; a section that only blocks once an inner one is entered counts with
+// the inner duration alone. Inside a section nested in another one on the same
+// resource, the task holds the larger of the two requests.
+func BlockingTime(t *Task, others []*Task, resourceList []*resources.Resource) float64 {
 	resourceMap := make(map[int]*resources.Resource)
 	for _, r := range resourceList {
 		resourceMap[r.ID] = r
 	}
 
-	for _, t := range taskSet {
-		preemptionLevel := t.Priority
-		for _, resID := range t.AssignedResIDs {
-			if r, ok := resourceMap[resID]; ok {
-				if r.Ceiling < preemptionLevel {
-					preemptionLevel = r.Ceiling
-				}
+	blocking := 0.0
+	for _, other := range others {
+		if other.PreemptionLevel <= t.PreemptionLevel {
+			continue
+		}
+		for i, cs := range other.CriticalSections {
+			r, ok := resourceMap[cs.ResourceID]
+			if ok && r.CeilingAt(r.Units-other.HeldUnits(i)) <= t.PreemptionLevel && cs.Duration > blocking {
+				blocking = cs.Duration
 			}
 		}
-		t.PreemptionLevel = preemptionLevel
 	}
+	return blocking
+}
+
+// hyperperiodResolution is the time granularity periods are rounded to when
+// computing the hyperperiod of a task set.
+const hyperperiodResolution = 1e-3
+
+// Hyperperiod returns the least common multiple of the task periods, with periods
+// rounded to hyperperiodResolution. It returns +Inf if the hyperperiod overflows,
+// which is typical for randomly generated (non-harmonic) periods.
+func Hyperperiod(taskSet []*Task) float64 {
+	lcm := int64(1)
+	for _, t := range taskSet {
+		period := int64(math.Round(t.Period / hyperperiodResolution))
+		if period <= 0 {
+			continue
+		}
+
+		a, b := lcm, period
+		for b != 0 {
+			a, b = b, a%b
+		}
+		factor := period / a
+		if lcm > math.MaxInt64/factor {
+			return math.Inf(1)
+		}
+		lcm *= factor
+	}
+	return float64(lcm) * hyperperiodResolution
+}
+
+// Scheduling algorithms selectable with the algorithm config field: EDF, EDF-VD,
+// deadline-monotonic, rate-monotonic and fixed-priority Adaptive Mixed Criticality.
+const (
+	EDF   = "edf"
+	EDFVD = "edf-vd"
+	DM    = "dm"
+	RM    = "rm"
+	AMC   = "amc"
+)
+
+// CriticalityUtilizations returns the total utilization of the LC tasks, and the
+// total utilization of the HC tasks with their WCET1 (LO) and WCET1+WCET2 (HI) budgets.
+func CriticalityUtilizations(taskSet []*Task) (lcLO, hcLO, hcHI float64) {
+	for _, t := range taskSet {
+		if t.Criticality == HC {
+			hcLO += t.Utilization()
+			hcHI += t.MaxUtilization()
+		} else {
+			lcLO += t.Utilization()
+		}
+	}
+	return lcLO, hcLO, hcHI
+}
+
+// VirtualDeadlineFactor returns the EDF-VD scaling factor x = U_HC(LO) / (1 - U_LC(LO))
+// by which relative deadlines of HC tasks are shrunk in Normal mode, and whether
+// the set passes the EDF-VD test x*U_LC(LO) + U_HC(HI) <= 1. If no factor in (0, 1]
+// exists, x = 1 (plain EDF) is returned.
+func VirtualDeadlineFactor(taskSet []*Task) (float64, bool) {
+	lcLO, hcLO, hcHI := CriticalityUtilizations(taskSet)
+	if lcLO >= 1 {
+		return 1, false
+	}
+
+	x := hcLO / (1 - lcLO)
+	if x <= 0 || x > 1 {
+		return 1, lcLO+hcHI <= 1
+	}
+	return x, x*lcLO+hcHI <= 1
 }
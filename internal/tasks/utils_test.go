@@ -0,0 +1,276 @@
+package tasks
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/resources"
+)
+
+// section returns a critical section on the resource.
+func section(resourceID, units int, start, duration float64) *CriticalSection {
+	return &CriticalSection{ResourceID: resourceID, Units: units, Start: start, Duration: duration}
+}
+
+// leveled returns a task with the preemption level and critical sections.
+func leveled(id, level int, sections ...*CriticalSection) *Task {
+	return &Task{ID: id, PreemptionLevel: level, Period: 10, Deadline: 10, WCET1: 5, CriticalSections: sections}
+}
+
+func TestComputeResourceCeilings(t *testing.T) {
+	const none = math.MaxInt32
+	tests := []struct {
+		name     string
+		units    int
+		taskSet  []*Task
+		ceilings []int
+		ceiling  int
+	}{
+		{
+			name:  "single unit",
+			units: 1,
+			taskSet: []*Task{
+				leveled(1, 1, section(1, 1, 0, 1)),
+				leveled(2, 2, section(1, 1, 0, 1)),
+			},
+			ceilings: []int{1, none},
+			ceiling:  1,
+		},
+		{
+			name:  "ceiling per available units",
+			units: 3,
+			taskSet: []*Task{
+				leveled(1, 1, section(1, 2, 0, 1)),
+				leveled(2, 2, section(1, 3, 0, 1)),
+				leveled(3, 3, section(1, 1, 0, 1)),
+			},
+			ceilings: []int{1, 1, 2, none},
+			ceiling:  1,
+		},
+		{
+			name:  "nested re-acquisition counts the larger request",
+			units: 2,
+			taskSet: []*Task{
+				leveled(1, 1, section(1, 1, 0, 2), section(1, 2, 0.5, 1)),
+				leveled(2, 2, section(2, 1, 0, 1)),
+			},
+			ceilings: []int{1, 1, none},
+			ceiling:  1,
+		},
+		{
+			name:  "unused resource",
+			units: 2,
+			taskSet: []*Task{
+				leveled(1, 1, section(2, 1, 0, 1)),
+			},
+			ceilings: []int{none, none, none},
+			ceiling:  none,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &resources.Resource{ID: 1, Units: tt.units}
+			ComputeResourceCeilings(tt.taskSet, []*resources.Resource{r})
+			if !reflect.DeepEqual(r.Ceilings, tt.ceilings) {
+				t.Errorf("ceilings = %v, want %v", r.Ceilings, tt.ceilings)
+			}
+			if r.Ceiling != tt.ceiling {
+				t.Errorf("ceiling = %d, want %d", r.Ceiling, tt.ceiling)
+			}
+			for available, want := range tt.ceilings {
+				if got := r.CeilingAt(available); got != want {
+					t.Errorf("CeilingAt(%d) = %d, want %d", available, got, want)
+				}
+			}
+			if got := r.CeilingAt(-1); got != tt.ceilings[0] {
+				t.Errorf("CeilingAt(-1) = %d, want %d", got, tt.ceilings[0])
+			}
+			if got := r.CeilingAt(tt.units + 1); got != none {
+				t.Errorf("CeilingAt(%d) = %d, want %d", tt.units+1, got, none)
+			}
+		})
+	}
+}
+
+func TestComputePreemptionLevels(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    config.Config
+		levels []int
+	}{
+		{
+			// Tasks 2 and 3 share a deadline and a level.
+			name:   "deadline",
+			levels: []int{3, 1, 1, 2},
+		},
+		{
+			name:   "rm",
+			cfg:    config.Config{PreemptionLevels: RMPreemptionLevels},
+			levels: []int{4, 3, 2, 1},
+		},
+		{
+			name:   "rm by default under AMC",
+			cfg:    config.Config{Algorithm: AMC},
+			levels: []int{4, 3, 2, 1},
+		},
+		{
+			// x = 0.1 / (1 - 0.3) shrinks the deadline of task 4 below 4.
+			name:   "virtual deadlines under EDF-VD",
+			cfg:    config.Config{Algorithm: EDFVD},
+			levels: []int{3, 2, 2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskSet := []*Task{
+				{ID: 1, Criticality: LC, Period: 40, Deadline: 20, WCET1: 4, Priority: 4},
+				{ID: 2, Criticality: LC, Period: 30, Deadline: 4, WCET1: 3, Priority: 3},
+				{ID: 3, Criticality: LC, Period: 20, Deadline: 4, WCET1: 2, Priority: 2},
+				{ID: 4, Criticality: HC, Period: 10, Deadline: 10, WCET1: 1, WCET2: 2, Priority: 1},
+			}
+			r := &resources.Resource{ID: 1, Units: 1}
+			taskSet[0].CriticalSections = []*CriticalSection{section(1, 1, 0, 1)}
+			ComputePreemptionLevels(&tt.cfg, taskSet, []*resources.Resource{r})
+			for i, task := range taskSet {
+				if task.PreemptionLevel != tt.levels[i] {
+					t.Errorf("level of task %d = %d, want %d", task.ID, task.PreemptionLevel, tt.levels[i])
+				}
+			}
+			if r.Ceiling != tt.levels[0] {
+				t.Errorf("ceiling = %d, want %d", r.Ceiling, tt.levels[0])
+			}
+		})
+	}
+}
+
+func TestBlockingTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []*resources.Resource
+		taskSet   []*Task
+		blocking  []float64
+	}{
+		{
+			name:      "no shared resources",
+			resources: []*resources.Resource{{ID: 1, Units: 1}},
+			taskSet: []*Task{
+				leveled(1, 1),
+				leveled(2, 2, section(1, 1, 0, 3)),
+			},
+			blocking: []float64{0, 0},
+		},
+		{
+			name:      "lower levels only block through the ceiling",
+			resources: []*resources.Resource{{ID: 1, Units: 3}},
+			taskSet: []*Task{
+				leveled(1, 1, section(1, 2, 0, 1)),
+				leveled(2, 2, section(1, 3, 0, 4)),
+				leveled(3, 3, section(1, 1, 0, 2)),
+			},
+			// Task 3 leaves 2 units, enough for task 1 but not for task 2.
+			blocking: []float64{4, 2, 0},
+		},
+		{
+			name:      "outer section only blocks once the inner one is entered",
+			resources: []*resources.Resource{{ID: 1, Units: 1}, {ID: 2, Units: 2}},
+			taskSet: []*Task{
+				leveled(1, 1, section(1, 1, 0, 1)),
+				leveled(2, 2, section(2, 1, 0, 5), section(1, 1, 2, 1)),
+			},
+			blocking: []float64{1, 0},
+		},
+		{
+			name:      "nested re-acquisition holds the larger request",
+			resources: []*resources.Resource{{ID: 1, Units: 3}},
+			taskSet: []*Task{
+				leveled(1, 1, section(1, 2, 0, 1)),
+				leveled(2, 2, section(1, 1, 0, 4), section(1, 2, 1, 1)),
+			},
+			blocking: []float64{1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ComputeResourceCeilings(tt.taskSet, tt.resources)
+			for i, task := range tt.taskSet {
+				if got := BlockingTime(task, tt.taskSet, tt.resources); got != tt.blocking[i] {
+					t.Errorf("BlockingTime(task %d) = %g, want %g", task.ID, got, tt.blocking[i])
+				}
+			}
+		})
+	}
+}
+
+func TestVirtualDeadlineFactor(t *testing.T) {
+	tests := []struct {
+		name        string
+		taskSet     []*Task
+		factor      float64
+		schedulable bool
+	}{
+		{
+			name: "factor in (0, 1]",
+			taskSet: []*Task{
+				{Criticality: LC, Period: 10, WCET1: 2},
+				{Criticality: HC, Period: 10, WCET1: 2, WCET2: 2},
+			},
+			factor:      0.25,
+			schedulable: true,
+		},
+		{
+			name: "HC overload",
+			taskSet: []*Task{
+				{Criticality: LC, Period: 10, WCET1: 5},
+				{Criticality: HC, Period: 10, WCET1: 2, WCET2: 7},
+			},
+			factor:      0.4,
+			schedulable: false,
+		},
+		{
+			name: "LC overload",
+			taskSet: []*Task{
+				{Criticality: LC, Period: 10, WCET1: 10},
+			},
+			factor:      1,
+			schedulable: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factor, schedulable := VirtualDeadlineFactor(tt.taskSet)
+			if math.Abs(factor-tt.factor) > 1e-9 || schedulable != tt.schedulable {
+				t.Errorf("VirtualDeadlineFactor() = %g, %v, want %g, %v", factor, schedulable, tt.factor, tt.schedulable)
+			}
+		})
+	}
+}
+
+func TestHyperperiod(t *testing.T) {
+	tests := []struct {
+		name        string
+		periods     []float64
+		hyperperiod float64
+	}{
+		{name: "integer periods", periods: []float64{5, 7, 11}, hyperperiod: 385},
+		{name: "fractional periods", periods: []float64{2.5, 4, 0}, hyperperiod: 20},
+		{name: "random periods", periods: []float64{57.123, 113.987, 199.457, 71.111, 93.777}, hyperperiod: math.Inf(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var taskSet []*Task
+			for _, period := range tt.periods {
+				taskSet = append(taskSet, &Task{Period: period})
+			}
+			if got := Hyperperiod(taskSet); math.Abs(got-tt.hyperperiod) > 1e-9 && got != tt.hyperperiod {
+				t.Errorf("Hyperperiod() = %g, want %g", got, tt.hyperperiod)
+			}
+		})
+	}
+}
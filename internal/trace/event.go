@@ -0,0 +1,160 @@
+package trace
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind identifies the type of a trace event.
+type Kind string
+
+const (
+	Note          Kind = "note"
+	Release       Kind = "release"
+	EarlyRelease  Kind = "early-release"
+	Skip          Kind = "skip"
+	Start         Kind = "start"
+	Preempt       Kind = "preempt"
+	CeilingBlock  Kind = "ceiling-block"
+	ResourceBlock Kind = "resource-block"
+	CSEnter       Kind = "cs-enter"
+	CSExit        Kind = "cs-exit"
+	ModeSwitch    Kind = "mode-switch"
+	Drop          Kind = "drop"
+	Complete      Kind = "complete"
+	Reclaim       Kind = "reclaim"
+	Miss          Kind = "miss"
+	Abort         Kind = "abort"
+	Inject        Kind = "inject"
+	Deadlock      Kind = "deadlock"
+)
+
+// Kinds lists all event kinds.
+var Kinds = []Kind{
+	Note, Release, EarlyRelease, Skip, Start, Preempt, CeilingBlock, ResourceBlock,
+	CSEnter, CSExit, ModeSwitch, Drop, Complete, Reclaim, Miss, Abort, Inject, Deadlock,
+}
+
+// ParseKinds parses a comma separated list of event kinds.
+func ParseKinds(list string) ([]Kind, error) {
+	var kinds []Kind
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, kind := range Kinds {
+			if string(kind) == name {
+				kinds = append(kinds, kind)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown trace event kind %q", name)
+		}
+	}
+	return kinds, nil
+}
+
+// Event is a scheduler event. Only the fields relevant to the kind of the event
+// are set; zero valued fields are left out of the JSON encoding.
+type Event struct {
+	Time   float64 `json:"time"`
+	Kind   Kind    `json:"kind"`
+	Mode   string  `json:"mode"`
+	JobID  int     `json:"job_id,omitempty"`
+	TaskID int     `json:"task_id,omitempty"`
+
+	// Timing of the job.
+	Deadline        float64 `json:"deadline,omitempty"`
+	VirtualDeadline float64 `json:"virtual_deadline,omitempty"`
+	Budget          float64 `json:"budget,omitempty"`
+	Demand          float64 `json:"demand,omitempty"`
+	ExecTime        float64 `json:"exec_time,omitempty"`
+
+	// SRP state.
+	PreemptionLevel int `json:"preemption_level,omitempty"`
+	SystemCeiling   int `json:"system_ceiling,omitempty"`
+	ResourceID      int `json:"resource_id,omitempty"`
+	Units           int `json:"units,omitempty"`
+	Available       int `json:"available,omitempty"`
+
+	// Job preempting the job of a preempt event.
+	OtherJobID    int     `json:"other_job_id,omitempty"`
+	OtherTaskID   int     `json:"other_task_id,omitempty"`
+	OtherDeadline float64 `json:"other_deadline,omitempty"`
+
+	// Mode switches.
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+	Reason string `json:"reason,omitempty"`
+
+	// Slack reclaimed, or time the release is delayed by.
+	Amount float64 `json:"amount,omitempty"`
+	Slack  float64 `json:"slack,omitempty"`
+
+	Policy  string `json:"policy,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// String formats the event as a human readable log line.
+func (e Event) String() string {
+	prefix := fmt.Sprintf("Time %.3f: ", e.Time)
+	switch e.Kind {
+	case Release:
+		return prefix + fmt.Sprintf("Released Job %d (Task %d, Deadline=%.3f, VirtualDeadline=%.3f, Budget=%.3f, Demand=%.3f) [Mode: %s]",
+			e.JobID, e.TaskID, e.Deadline, e.VirtualDeadline, e.Budget, e.Demand, e.Mode)
+	case EarlyRelease:
+		return prefix + fmt.Sprintf("EARLY RELEASE of Task %d [Slack=%.3f]", e.TaskID, e.Slack)
+	case Skip:
+		return prefix + fmt.Sprintf("SKIPPED release of Task %d after a deadline miss", e.TaskID)
+	case Start:
+		return prefix + fmt.Sprintf("Starting Job %d (Task %d) with Deadline=%.3f, PreemptionLevel=%d, SystemCeiling=%d",
+			e.JobID, e.TaskID, e.VirtualDeadline, e.PreemptionLevel, e.SystemCeiling)
+	case Preempt:
+		return prefix + fmt.Sprintf("Preempting Job %d (Task %d, Deadline=%.3f) with Job %d (Task %d, Deadline=%.3f)",
+			e.JobID, e.TaskID, e.VirtualDeadline, e.OtherJobID, e.OtherTaskID, e.OtherDeadline)
+	case CeilingBlock:
+		return prefix + fmt.Sprintf("Job %d (Task %d) BLOCKED by system ceiling [PreemptionLevel=%d, SystemCeiling=%d]",
+			e.JobID, e.TaskID, e.PreemptionLevel, e.SystemCeiling)
+	case ResourceBlock:
+		return prefix + fmt.Sprintf("Job %d (Task %d) BLOCKED on Resource %d [Requested=%d, Available=%d]",
+			e.JobID, e.TaskID, e.ResourceID, e.Units, e.Available)
+	case CSEnter:
+		return prefix + fmt.Sprintf("Job %d (Task %d) ENTERS critical section on Resource %d [Units=%d, Available=%d, SystemCeiling=%d]",
+			e.JobID, e.TaskID, e.ResourceID, e.Units, e.Available, e.SystemCeiling)
+	case CSExit:
+		return prefix + fmt.Sprintf("Job %d (Task %d) EXITS critical section on Resource %d [Units=%d, Available=%d, SystemCeiling=%d]",
+			e.JobID, e.TaskID, e.ResourceID, e.Units, e.Available, e.SystemCeiling)
+	case ModeSwitch:
+		if e.JobID != 0 {
+			return prefix + fmt.Sprintf("Mode switch to %s triggered by Job %d (Task %d) [ExecTime=%.3f, Budget=%.3f]",
+				strings.ToUpper(e.To), e.JobID, e.TaskID, e.ExecTime, e.Budget)
+		}
+		return prefix + fmt.Sprintf("Mode switch to %s (%s)", strings.ToUpper(e.To), e.Reason)
+	case Drop:
+		return prefix + fmt.Sprintf("Dropped LC Job %d (Task %d) due to mode switch", e.JobID, e.TaskID)
+	case Complete:
+		return prefix + fmt.Sprintf("COMPLETED Job %d (Task %d) [FinishTime=%.3f, Total ExecTime=%.3f]",
+			e.JobID, e.TaskID, e.Time, e.ExecTime)
+	case Reclaim:
+		return prefix + fmt.Sprintf("Reclaimed %.3f slack from Job %d (Task %d) [Slack=%.3f]",
+			e.Amount, e.JobID, e.TaskID, e.Slack)
+	case Miss:
+		return prefix + fmt.Sprintf("MISSED Deadline for Job %d (Task %d) [Deadline=%.3f, ExecTime=%.3f, Policy=%s]",
+			e.JobID, e.TaskID, e.Deadline, e.ExecTime, e.Policy)
+	case Abort:
+		return prefix + fmt.Sprintf("ABORTED Job %d (Task %d)", e.JobID, e.TaskID)
+	case Inject:
+		if e.JobID == 0 {
+			return prefix + fmt.Sprintf("INJECTED release delay of %.3f for Task %d", e.Amount, e.TaskID)
+		}
+		return prefix + fmt.Sprintf("INJECTED into Job %d (Task %d): %s", e.JobID, e.TaskID, e.Message)
+	case Deadlock:
+		return prefix + "DEADLOCK: " + e.Message
+	default:
+		return prefix + e.Message
+	}
+}
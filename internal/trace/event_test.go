@@ -0,0 +1,61 @@
+package trace
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKinds(t *testing.T) {
+	tests := []struct {
+		list  string
+		kinds []Kind
+		valid bool
+	}{
+		{list: "", valid: true},
+		{list: "release", kinds: []Kind{Release}, valid: true},
+		{list: " mode-switch , miss,", kinds: []Kind{ModeSwitch, Miss}, valid: true},
+		{list: "release,Release"},
+		{list: "overrun"},
+	}
+
+	for _, tt := range tests {
+		kinds, err := ParseKinds(tt.list)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseKinds(%q) error = %v, want valid %v", tt.list, err, tt.valid)
+			continue
+		}
+		if !reflect.DeepEqual(kinds, tt.kinds) {
+			t.Errorf("ParseKinds(%q) = %v, want %v", tt.list, kinds, tt.kinds)
+		}
+	}
+}
+
+func TestEventString(t *testing.T) {
+	tests := []struct {
+		event Event
+		line  string
+	}{
+		{
+			event: Event{Time: 3, Kind: ModeSwitch, JobID: 2, TaskID: 1, To: "Overrun", ExecTime: 2, Budget: 2},
+			line:  "Time 3.000: Mode switch to OVERRUN triggered by Job 2 (Task 1) [ExecTime=2.000, Budget=2.000]",
+		},
+		{
+			event: Event{Time: 4, Kind: ModeSwitch, To: "Normal", Reason: "idle"},
+			line:  "Time 4.000: Mode switch to NORMAL (idle)",
+		},
+		{
+			event: Event{Time: 5, Kind: Inject, TaskID: 1, Amount: 3},
+			line:  "Time 5.000: INJECTED release delay of 3.000 for Task 1",
+		},
+		{
+			event: Event{Time: 0, Kind: Note, Message: "Random seed 1"},
+			line:  "Time 0.000: Random seed 1",
+		},
+	}
+
+	for _, tt := range tests {
+		if line := tt.event.String(); line != tt.line {
+			t.Errorf("String() = %q, want %q", line, tt.line)
+		}
+	}
+}
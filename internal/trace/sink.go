@@ -0,0 +1,85 @@
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Sink receives the events of a simulation run.
+type Sink interface {
+	Emit(e Event)
+}
+
+// JSONLSink writes events as JSON Lines, one JSON object per event.
+type JSONLSink struct {
+	encoder *json.Encoder
+	err     error
+}
+
+// NewJSONLSink returns a sink writing JSON Lines to w.
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{encoder: json.NewEncoder(w)}
+}
+
+func (s *JSONLSink) Emit(e Event) {
+	if s.err == nil {
+		s.err = s.encoder.Encode(e)
+	}
+}
+
+// Err returns the first error encountered while writing events.
+func (s *JSONLSink) Err() error {
+	return s.err
+}
+
+// ConsoleSink writes events as human readable log lines.
+type ConsoleSink struct {
+	w io.Writer
+}
+
+// NewConsoleSink returns a sink writing log lines to w.
+func NewConsoleSink(w io.Writer) *ConsoleSink {
+	return &ConsoleSink{w: w}
+}
+
+func (s *ConsoleSink) Emit(e Event) {
+	fmt.Fprintln(s.w, e)
+}
+
+// filterSink forwards the events matching the filter to another sink.
+type filterSink struct {
+	sink  Sink
+	kinds map[Kind]bool
+	tasks map[int]bool
+}
+
+// Filter returns a sink forwarding to sink only the events of the given kinds
+// involving the given tasks. An empty list accepts everything. Events not tied
+// to a task, such as mode switches back to Normal mode, pass the task filter.
+func Filter(sink Sink, kinds []Kind, taskIDs []int) Sink {
+	f := &filterSink{sink: sink}
+	if len(kinds) > 0 {
+		f.kinds = make(map[Kind]bool)
+		for _, kind := range kinds {
+			f.kinds[kind] = true
+		}
+	}
+	if len(taskIDs) > 0 {
+		f.tasks = make(map[int]bool)
+		for _, id := range taskIDs {
+			f.tasks[id] = true
+		}
+	}
+	return f
+}
+
+func (f *filterSink) Emit(e Event) {
+	if f.kinds != nil && !f.kinds[e.Kind] {
+		return
+	}
+	if f.tasks != nil && e.TaskID != 0 && !f.tasks[e.TaskID] && !f.tasks[e.OtherTaskID] {
+		return
+	}
+	f.sink.Emit(e)
+}
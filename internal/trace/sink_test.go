@@ -0,0 +1,74 @@
+package trace
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// recorder is a sink keeping the events it receives.
+type recorder struct {
+	events []Event
+}
+
+func (r *recorder) Emit(e Event) {
+	r.events = append(r.events, e)
+}
+
+func TestFilter(t *testing.T) {
+	events := []Event{
+		{Kind: Release, TaskID: 1},
+		{Kind: Release, TaskID: 2},
+		{Kind: Preempt, TaskID: 3, OtherTaskID: 1},
+		{Kind: ModeSwitch, To: "Normal"},
+		{Kind: Miss, TaskID: 2},
+	}
+
+	tests := []struct {
+		name    string
+		kinds   []Kind
+		taskIDs []int
+		want    []int
+	}{
+		{name: "everything", want: []int{0, 1, 2, 3, 4}},
+		{name: "kinds", kinds: []Kind{Release, Miss}, want: []int{0, 1, 4}},
+		// Events involving the task as the other job, and events tied to no
+		// task, pass the task filter.
+		{name: "tasks", taskIDs: []int{1}, want: []int{0, 2, 3}},
+		{name: "kinds and tasks", kinds: []Kind{Release}, taskIDs: []int{2}, want: []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			sink := Filter(r, tt.kinds, tt.taskIDs)
+			for _, e := range events {
+				sink.Emit(e)
+			}
+
+			var want []Event
+			for _, i := range tt.want {
+				want = append(want, events[i])
+			}
+			if !reflect.DeepEqual(r.events, want) {
+				t.Errorf("forwarded %v, want %v", r.events, want)
+			}
+		})
+	}
+}
+
+func TestJSONLSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLSink(&buf)
+	sink.Emit(Event{Time: 1.5, Kind: Release, Mode: "Normal", JobID: 1, TaskID: 2, Deadline: 6.5})
+	sink.Emit(Event{Time: 2, Kind: Note, Mode: "Normal", Message: "done"})
+	if err := sink.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"time":1.5,"kind":"release","mode":"Normal","job_id":1,"task_id":2,"deadline":6.5}` + "\n" +
+		`{"time":2,"kind":"note","mode":"Normal","message":"done"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("JSON Lines = %q, want %q", buf.String(), want)
+	}
+}
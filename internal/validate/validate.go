@@ -0,0 +1,516 @@
+package validate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/resources"
+	"github.com/99109766/fms-scheduler/internal/scenario"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// tolerance absorbs rounding errors when comparing time instants and durations.
+const tolerance = 1e-6
+
+// Invariants checked on a recorded schedule.
+const (
+	SingleProcessor = "single-processor"
+	ExactExecution  = "exact-execution"
+	WCETBudget      = "wcet-budget"
+	ReleaseGrid     = "release-grid"
+	NoEarlyStart    = "no-early-start"
+	ResourceUnits   = "resource-units"
+	SRPAdmission    = "srp-admission"
+	NoRunAfterEnd   = "no-run-after-end"
+	NoLCInOverrun   = "no-lc-in-overrun"
+)
+
+// Violation is a broken invariant.
+type Violation struct {
+	Rule    string  `json:"rule"`
+	Time    float64 `json:"time"`
+	JobID   int     `json:"job_id,omitempty"`
+	Message string  `json:"message"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("Time %.3f: [%s] %s", v.Time, v.Rule, v.Message)
+}
+
+// Validate checks the recorded schedule against the scheduling invariants,
+// independently of the simulator that produced it. Bounds come from the task
+// set rather than from the job records whenever the task set defines them:
+//   - at most one job runs at a time,
+//   - every completed job executes exactly its demand, and no job executes more
+//     than the budget of its task, WCET1 for LC tasks and WCET1+WCET2 for HC tasks,
+//   - every task is released at 0 and then one period after its previous
+//     release, later only by the delays injected by the scenario or, for LC tasks
+//     released early in Overrun mode, up to their extended period, and the
+//     shifted releases recorded by the simulator are exactly the late ones,
+//   - a release without a job is an LC release in Overrun mode without early
+//     release, or a release skipped after a deadline miss under the skip policy,
+//   - no job runs before its release,
+//   - the units held of a resource never exceed its capacity,
+//   - under SRP, a job only starts if its preemption level is higher than the
+//     system ceiling, computed from the preemption levels of the tasks and the
+//     units held by the other jobs,
+//   - no job runs after it completed, or was dropped or aborted,
+//   - without early release, no LC job runs in Overrun mode, as delimited by the
+//     recorded mode switches.
+//
+// Early release, the extended LC periods, the deadline miss policy and the
+// delayed releases are taken from the options, and default to a simulation with
+// the zero configuration and no scenario.
+func Validate(result *scheduler.Result, taskSet []*tasks.Task, resourceList []*resources.Resource, opts ...Option) []Violation {
+	v := &validator{
+		result:           result,
+		taskSet:          taskSet,
+		resourceList:     resourceList,
+		jobs:             make(map[int]scheduler.JobRecord),
+		byID:             make(map[int]*tasks.Task),
+		lcMaxPeriodRatio: 1,
+		missPolicy:       scheduler.MissAbort,
+	}
+	for _, job := range result.Jobs {
+		v.jobs[job.JobID] = job
+	}
+	for _, t := range taskSet {
+		v.byID[t.ID] = t
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	v.checkSingleProcessor()
+	v.checkJobs()
+	v.checkReleases()
+	v.checkResourceUnits()
+	if !v.earlyRelease {
+		v.checkOverrunLC()
+	}
+	if result.Protocol == "" || result.Protocol == scheduler.ProtocolSRP {
+		v.checkSRPAdmission()
+	}
+
+	sort.SliceStable(v.violations, func(i, j int) bool {
+		return v.violations[i].Time < v.violations[j].Time
+	})
+	return v.violations
+}
+
+// Option tells Validate how the schedule was simulated.
+type Option func(*validator)
+
+// WithConfig validates the schedule against the configuration it was simulated
+// with: early release, unless the scheduling algorithm suspends LC tasks in
+// Overrun mode, the extended LC periods and the deadline miss policy.
+func WithConfig(cfg *config.Config) Option {
+	return func(v *validator) {
+		v.earlyRelease = cfg.EarlyRelease
+		if policy, err := scheduler.NewPolicy(cfg.Algorithm); err == nil && policy.SuspendsLC() {
+			v.earlyRelease = false
+		}
+		v.lcMaxPeriodRatio = math.Max(cfg.LCMaxPeriodRatio, 1)
+		if cfg.MissPolicy != "" {
+			v.missPolicy = cfg.MissPolicy
+		}
+	}
+}
+
+// WithScenario validates the schedule against the releases delayed by the
+// scenario it was simulated with.
+func WithScenario(sc *scenario.Scenario) Option {
+	return func(v *validator) {
+		for _, injection := range sc.Injections {
+			if injection.Kind == scenario.DelayedRelease {
+				v.delays = append(v.delays, injection)
+			}
+		}
+	}
+}
+
+type validator struct {
+	result       *scheduler.Result
+	taskSet      []*tasks.Task
+	resourceList []*resources.Resource
+	jobs         map[int]scheduler.JobRecord
+	byID         map[int]*tasks.Task
+	violations   []Violation
+
+	earlyRelease     bool
+	lcMaxPeriodRatio float64
+	missPolicy       string
+	delays           []scenario.Injection
+}
+
+func (v *validator) report(rule string, time float64, jobID int, format string, args ...any) {
+	v.violations = append(v.violations, Violation{Rule: rule, Time: time, JobID: jobID, Message: fmt.Sprintf(format, args...)})
+}
+
+// sortedSchedule returns the execution intervals ordered by start time.
+func (v *validator) sortedSchedule() []scheduler.Schedule {
+	sorted := make([]scheduler.Schedule, len(v.result.Schedule))
+	copy(sorted, v.result.Schedule)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartTime < sorted[j].StartTime
+	})
+	return sorted
+}
+
+// checkSingleProcessor checks that the execution intervals do not overlap.
+func (v *validator) checkSingleProcessor() {
+	sorted := v.sortedSchedule()
+	for i := 1; i < len(sorted); i++ {
+		prev, cur := sorted[i-1], sorted[i]
+		if cur.StartTime < prev.EndTime-tolerance {
+			v.report(SingleProcessor, cur.StartTime, cur.JobID,
+				"Job %d (Task %d) runs while Job %d (Task %d) runs until %.3f",
+				cur.JobID, cur.TaskID, prev.JobID, prev.TaskID, prev.EndTime)
+		}
+	}
+}
+
+// checkJobs checks the execution intervals of each job against its record.
+func (v *validator) checkJobs() {
+	executed := make(map[int]float64)
+	for _, s := range v.result.Schedule {
+		executed[s.JobID] += s.EndTime - s.StartTime
+
+		job, ok := v.jobs[s.JobID]
+		if !ok {
+			v.report(ExactExecution, s.StartTime, s.JobID, "Job %d (Task %d) runs but was never released", s.JobID, s.TaskID)
+			continue
+		}
+		if s.StartTime < job.ReleaseTime-tolerance {
+			v.report(NoEarlyStart, s.StartTime, s.JobID, "Job %d (Task %d) runs before its release at %.3f",
+				s.JobID, s.TaskID, job.ReleaseTime)
+		}
+		if job.Outcome != scheduler.OutcomeUnfinished && s.EndTime > job.FinishTime+tolerance {
+			v.report(NoRunAfterEnd, math.Max(s.StartTime, job.FinishTime), s.JobID, "Job %d (Task %d) runs after it was %s at %.3f",
+				s.JobID, s.TaskID, job.Outcome, job.FinishTime)
+		}
+	}
+
+	for _, job := range v.result.Jobs {
+		if job.Outcome == scheduler.OutcomeCompleted && math.Abs(executed[job.JobID]-job.Demand) > tolerance {
+			v.report(ExactExecution, job.FinishTime, job.JobID, "Job %d (Task %d) completed after executing %.6f instead of its demand %.6f",
+				job.JobID, job.TaskID, executed[job.JobID], job.Demand)
+		}
+		if t, ok := v.byID[job.TaskID]; ok && executed[job.JobID] > budget(t)+tolerance {
+			v.report(WCETBudget, job.FinishTime, job.JobID, "Job %d (Task %d) executed %.6f, more than the %v budget %.6f of its task",
+				job.JobID, job.TaskID, executed[job.JobID], t.Criticality, budget(t))
+		}
+	}
+}
+
+// budget returns the largest execution time of a job of the task: WCET1 for LC
+// tasks and WCET1+WCET2 for HC tasks.
+func budget(t *tasks.Task) float64 {
+	if t.Criticality == tasks.HC {
+		return t.WCET1 + t.WCET2
+	}
+	return t.WCET1
+}
+
+// Kinds of releases.
+const (
+	jobRelease        = "job"
+	suppressedRelease = "suppressed"
+	skippedRelease    = "skipped"
+)
+
+// release is a release of a task, with or without a job.
+type release struct {
+	time  float64
+	jobID int
+	kind  string
+}
+
+// checkReleases rebuilds the releases of each task from its period, the delays
+// injected by the scenario and the ER-EDF early release window, and checks the
+// jobs, suppressed and skipped releases against them. The shifted releases
+// recorded by the simulator are only cross-checked.
+func (v *validator) checkReleases() {
+	releases := make(map[int][]release)
+	for _, job := range v.result.Jobs {
+		if _, ok := v.byID[job.TaskID]; !ok {
+			v.report(ReleaseGrid, job.ReleaseTime, job.JobID, "Job %d belongs to unknown Task %d", job.JobID, job.TaskID)
+			continue
+		}
+		releases[job.TaskID] = append(releases[job.TaskID], release{job.ReleaseTime, job.JobID, jobRelease})
+	}
+	for _, r := range v.result.Suppressed {
+		releases[r.TaskID] = append(releases[r.TaskID], release{r.Time, 0, suppressedRelease})
+	}
+	for _, r := range v.result.Skipped {
+		releases[r.TaskID] = append(releases[r.TaskID], release{r.Time, 0, skippedRelease})
+	}
+	shifted := make(map[int][]scheduler.ShiftedRelease)
+	for _, r := range v.result.Shifted {
+		shifted[r.TaskID] = append(shifted[r.TaskID], r)
+	}
+
+	delayed := make([]bool, len(v.delays))
+	for _, t := range v.taskSet {
+		list := releases[t.ID]
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].time < list[j].time
+		})
+
+		matched := make([]bool, len(shifted[t.ID]))
+		jobs, skips := 0, 0
+		for i, r := range list {
+			nominal := 0.0
+			if i > 0 {
+				nominal = list[i-1].time + t.Period
+				if r.time-list[i-1].time <= tolerance {
+					v.report(ReleaseGrid, r.time, r.jobID, "Task %d is released twice at %.3f", t.ID, r.time)
+				}
+			}
+
+			earliest := v.delayRelease(t, jobs+1, nominal, delayed)
+			latest := earliest
+			if i > 0 && v.releasesElastic(t, earliest) {
+				latest = math.Max(earliest, list[i-1].time+t.Period*v.lcMaxPeriodRatio)
+			}
+			switch {
+			case r.time >= earliest-tolerance && r.time <= latest+tolerance:
+			case latest > earliest:
+				v.report(ReleaseGrid, r.time, r.jobID, "Task %d is released at %.3f outside [%.3f, %.3f]", t.ID, r.time, earliest, latest)
+			default:
+				v.report(ReleaseGrid, r.time, r.jobID, "Task %d is released at %.3f instead of %.3f", t.ID, r.time, earliest)
+			}
+
+			if r.time > nominal+tolerance {
+				if k := findShifted(shifted[t.ID], r.time, nominal); k >= 0 {
+					matched[k] = true
+				} else {
+					v.report(ReleaseGrid, r.time, r.jobID, "Task %d is released at %.3f, later than %.3f, but the release is not recorded as shifted",
+						t.ID, r.time, nominal)
+				}
+			}
+
+			switch r.kind {
+			case jobRelease:
+				jobs++
+				if !v.releases(t, r.time) {
+					v.report(ReleaseGrid, r.time, r.jobID, "LC Task %d is released at %.3f in Overrun mode without early release", t.ID, r.time)
+				}
+			case suppressedRelease:
+				if !v.suppresses(t, r.time) {
+					v.report(ReleaseGrid, r.time, 0, "Task %d has no job released at %.3f, but only LC tasks are suspended in Overrun mode without early release",
+						t.ID, r.time)
+				}
+			case skippedRelease:
+				skips++
+				if v.missPolicy != scheduler.MissSkip || skips > v.missesBy(t, r.time) {
+					v.report(ReleaseGrid, r.time, 0, "Task %d skips its release at %.3f without a deadline miss to catch up on", t.ID, r.time)
+				}
+			}
+		}
+
+		for k, r := range shifted[t.ID] {
+			if !matched[k] {
+				v.report(ReleaseGrid, r.Time, 0, "Task %d is recorded as shifted from %.3f to %.3f, which is not one of its late releases",
+					t.ID, r.Nominal, r.Time)
+			}
+		}
+	}
+}
+
+// delayRelease returns the time the index-th job of the task is released at when
+// its release is due at the given time, delayed by the scenario injections that
+// target it. As in the simulator, an injection applies once, and a release
+// delayed past the time of another injection is delayed by it as well.
+func (v *validator) delayRelease(t *tasks.Task, index int, releaseTime float64, delayed []bool) float64 {
+	for v.releases(t, releaseTime) {
+		delay := 0.0
+		for i, injection := range v.delays {
+			if !delayed[i] && injection.Matches(t.ID, index, releaseTime+tolerance) {
+				delayed[i] = true
+				delay += injection.Delay
+			}
+		}
+		if delay <= 0 {
+			break
+		}
+		releaseTime += delay
+	}
+	return releaseTime
+}
+
+// modesAt returns the modes of the system just before and just after the
+// instant. They only differ at a mode switch, at which both are accepted since
+// the recorded schedule does not order the events of an instant.
+func (v *validator) modesAt(time float64) (before, after scheduler.Mode) {
+	for _, ms := range v.result.ModeSwitches {
+		if ms.Time < time-tolerance {
+			before = ms.To
+		}
+		if ms.Time <= time+tolerance {
+			after = ms.To
+		}
+	}
+	return before, after
+}
+
+// releases reports whether a job of the task may be released at the instant:
+// LC tasks are suspended in Overrun mode unless they are released early.
+func (v *validator) releases(t *tasks.Task, time float64) bool {
+	before, after := v.modesAt(time)
+	return t.Criticality == tasks.HC || v.earlyRelease || before == scheduler.Normal || after == scheduler.Normal
+}
+
+// suppresses reports whether a release of the task at the instant may have no job.
+func (v *validator) suppresses(t *tasks.Task, time float64) bool {
+	before, after := v.modesAt(time)
+	return t.Criticality == tasks.LC && !v.earlyRelease && (before == scheduler.Overrun || after == scheduler.Overrun)
+}
+
+// releasesElastic reports whether a release of the task due at the instant may
+// be postponed until slack is reclaimed, up to its extended period: LC tasks are
+// released early in Overrun mode under ER-EDF.
+func (v *validator) releasesElastic(t *tasks.Task, time float64) bool {
+	before, after := v.modesAt(time)
+	return t.Criticality == tasks.LC && v.earlyRelease && (before == scheduler.Overrun || after == scheduler.Overrun)
+}
+
+// missesBy returns the number of jobs of the task that missed their deadline by
+// the instant.
+func (v *validator) missesBy(t *tasks.Task, time float64) int {
+	misses := 0
+	for _, job := range v.result.Jobs {
+		if job.TaskID == t.ID && job.Missed && job.Deadline <= time+tolerance {
+			misses++
+		}
+	}
+	return misses
+}
+
+// findShifted returns the index of the recorded shifted release at the instant
+// from the nominal release, or -1.
+func findShifted(shifted []scheduler.ShiftedRelease, time, nominal float64) int {
+	for i, r := range shifted {
+		if math.Abs(r.Time-time) <= tolerance && math.Abs(r.Nominal-nominal) <= tolerance {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkOverrunLC checks that no LC job runs while the system is in Overrun mode.
+func (v *validator) checkOverrunLC() {
+	type interval struct{ start, end float64 }
+	var overrun []interval
+	for _, ms := range v.result.ModeSwitches {
+		switch {
+		case ms.To == scheduler.Overrun:
+			overrun = append(overrun, interval{ms.Time, v.result.SimulateTime})
+		case len(overrun) > 0:
+			overrun[len(overrun)-1].end = ms.Time
+		}
+	}
+
+	for _, s := range v.result.Schedule {
+		if t, ok := v.byID[s.TaskID]; !ok || t.Criticality != tasks.LC {
+			continue
+		}
+		for _, o := range overrun {
+			if s.StartTime < o.end-tolerance && s.EndTime > o.start+tolerance {
+				v.report(NoLCInOverrun, math.Max(s.StartTime, o.start), s.JobID, "LC Job %d (Task %d) runs in Overrun mode from %.3f",
+					s.JobID, s.TaskID, o.start)
+			}
+		}
+	}
+}
+
+// checkResourceUnits checks that the units held of each resource never exceed its capacity.
+func (v *validator) checkResourceUnits() {
+	capacity := make(map[int]int)
+	for _, r := range v.resourceList {
+		capacity[r.ID] = r.Units
+	}
+
+	type change struct {
+		time  float64
+		delta int
+	}
+	changes := make(map[int][]change)
+	for _, h := range v.result.Holds {
+		if _, ok := capacity[h.ResourceID]; !ok {
+			v.report(ResourceUnits, h.Start, h.JobID, "Job %d (Task %d) holds unknown Resource %d", h.JobID, h.TaskID, h.ResourceID)
+			continue
+		}
+		if h.End > h.Start {
+			changes[h.ResourceID] = append(changes[h.ResourceID], change{h.Start, h.Units}, change{h.End, -h.Units})
+		}
+	}
+
+	for _, r := range v.resourceList {
+		list := changes[r.ID]
+		// Releases at an instant come before acquisitions.
+		sort.SliceStable(list, func(i, j int) bool {
+			if math.Abs(list[i].time-list[j].time) > tolerance {
+				return list[i].time < list[j].time
+			}
+			return list[i].delta < list[j].delta
+		})
+
+		held := 0
+		for _, c := range list {
+			held += c.delta
+			if held > r.Units {
+				v.report(ResourceUnits, c.time, 0, "%d units of Resource %d are held, but it only has %d", held, r.ID, r.Units)
+			}
+		}
+	}
+}
+
+// checkSRPAdmission checks that every job started with a preemption level higher
+// than the system ceiling at that instant.
+func (v *validator) checkSRPAdmission() {
+	started := make(map[int]bool)
+	for _, s := range v.sortedSchedule() {
+		if started[s.JobID] {
+			continue
+		}
+		started[s.JobID] = true
+
+		t, ok := v.byID[s.TaskID]
+		if !ok {
+			continue
+		}
+		if ceiling := v.systemCeiling(s.StartTime, s.JobID); t.PreemptionLevel >= ceiling {
+			v.report(SRPAdmission, s.StartTime, s.JobID, "Job %d (Task %d) started with preemption level %d while the system ceiling is %d",
+				s.JobID, s.TaskID, t.PreemptionLevel, ceiling)
+		}
+	}
+}
+
+// systemCeiling returns the SRP system ceiling at the instant, given the units
+// held by the jobs other than the excluded one: the highest preemption level of
+// the tasks needing more units of a resource than left available.
+func (v *validator) systemCeiling(time float64, excluded int) int {
+	available := make(map[int]int)
+	for _, r := range v.resourceList {
+		available[r.ID] = r.Units
+	}
+	for _, h := range v.result.Holds {
+		if h.JobID != excluded && h.Start <= time+tolerance && h.End > time+tolerance {
+			available[h.ResourceID] -= h.Units
+		}
+	}
+
+	ceiling := math.MaxInt32
+	for _, r := range v.resourceList {
+		for _, t := range v.taskSet {
+			if t.ResourceDemand(r.ID) > available[r.ID] && t.PreemptionLevel < ceiling {
+				ceiling = t.PreemptionLevel
+			}
+		}
+	}
+	return ceiling
+}
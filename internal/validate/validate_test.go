@@ -0,0 +1,229 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/99109766/fms-scheduler/config"
+	"github.com/99109766/fms-scheduler/internal/resources"
+	"github.com/99109766/fms-scheduler/internal/scenario"
+	"github.com/99109766/fms-scheduler/internal/scheduler"
+	"github.com/99109766/fms-scheduler/internal/tasks"
+)
+
+// cleanResult simulates three tasks, two of them sharing a single-unit resource,
+// under EDF and SRP. The second job of task 2 overruns its WCET1 at 9 and the
+// system returns to Normal mode at the next idle instant.
+func cleanResult(t *testing.T) (*scheduler.Result, []*tasks.Task, []*resources.Resource) {
+	t.Helper()
+	taskSet := []*tasks.Task{
+		{ID: 1, Criticality: tasks.LC, Period: 5, Deadline: 5, WCET1: 1, PreemptionLevel: 1,
+			CriticalSections: []*tasks.CriticalSection{{ResourceID: 1, Units: 1, Start: 0.5, Duration: 0.5}}},
+		{ID: 2, Criticality: tasks.HC, Period: 7, Deadline: 7, WCET1: 2, WCET2: 1, PreemptionLevel: 2},
+		{ID: 3, Criticality: tasks.LC, Period: 11, Deadline: 11, WCET1: 3, PreemptionLevel: 3,
+			CriticalSections: []*tasks.CriticalSection{{ResourceID: 1, Units: 1, Start: 0, Duration: 2}}},
+	}
+	resourceList := []*resources.Resource{{ID: 1, Units: 1}}
+	tasks.ComputeResourceCeilings(taskSet, resourceList)
+
+	cfg := &config.Config{
+		SimulateTime: 30,
+		ModeReturn:   scheduler.ReturnIdle,
+		ExecModel:    config.ExecModelConfig{Kind: scheduler.TableExecution, Table: map[int][]float64{2: {2, 3}}},
+		Quiet:        true,
+	}
+	result, err := scheduler.RunScheduler(cfg, taskSet, resourceList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result, taskSet, resourceList
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(r *scheduler.Result)
+		opts    []Option
+		rule    string
+
+		// absent reports that the corruption must not break the rule.
+		absent bool
+	}{
+		{
+			name:    "overlapping intervals",
+			corrupt: func(r *scheduler.Result) { r.Schedule[1].StartTime = 0.5 },
+			rule:    SingleProcessor,
+		},
+		{
+			name:    "completed job short of its demand",
+			corrupt: func(r *scheduler.Result) { r.Schedule[0].EndTime = 0.5 },
+			rule:    ExactExecution,
+		},
+		{
+			name: "unreleased job",
+			corrupt: func(r *scheduler.Result) {
+				r.Schedule = append(r.Schedule, scheduler.Schedule{TaskID: 1, JobID: 99, StartTime: 10.2, EndTime: 10.5})
+			},
+			rule: ExactExecution,
+		},
+		{
+			name: "demand beyond WCET1",
+			corrupt: func(r *scheduler.Result) {
+				r.Jobs[0].Demand = 1.5
+				r.Schedule[0].EndTime = 1.5
+			},
+			rule: WCETBudget,
+		},
+		{
+			name:    "release off the grid",
+			corrupt: func(r *scheduler.Result) { r.Jobs[4].ReleaseTime = 8 },
+			rule:    ReleaseGrid,
+		},
+		{
+			name:    "missing release",
+			corrupt: func(r *scheduler.Result) { removeJob(r, 7) },
+			rule:    ReleaseGrid,
+		},
+		{
+			name:    "unrecorded suppression",
+			corrupt: func(r *scheduler.Result) { r.Suppressed = nil },
+			rule:    ReleaseGrid,
+		},
+		{
+			// A late release is rejected even if recorded as shifted, since no
+			// scenario delays it.
+			name: "late release recorded as shifted",
+			corrupt: func(r *scheduler.Result) {
+				job := findJob(r, 7)
+				r.Shifted = append(r.Shifted, scheduler.ShiftedRelease{Time: job.ReleaseTime + 0.5, Nominal: job.ReleaseTime, TaskID: job.TaskID})
+				job.ReleaseTime += 0.5
+			},
+			rule: ReleaseGrid,
+		},
+		{
+			name: "skip without a deadline miss",
+			corrupt: func(r *scheduler.Result) {
+				job := *findJob(r, 7)
+				removeJob(r, 7)
+				r.Skipped = append(r.Skipped, scheduler.Suppression{Time: job.ReleaseTime, TaskID: job.TaskID})
+			},
+			opts: []Option{WithConfig(&config.Config{MissPolicy: scheduler.MissSkip})},
+			rule: ReleaseGrid,
+		},
+		{
+			name:    "start before release",
+			corrupt: func(r *scheduler.Result) { r.Schedule[6].StartTime = 10.5 },
+			rule:    NoEarlyStart,
+		},
+		{
+			name: "resource over-allocated",
+			corrupt: func(r *scheduler.Result) {
+				r.Holds = append(r.Holds, scheduler.Hold{JobID: 3, TaskID: 3, ResourceID: 1, Units: 1, Start: 0.5, End: 1})
+			},
+			rule: ResourceUnits,
+		},
+		{
+			name: "unknown resource",
+			corrupt: func(r *scheduler.Result) {
+				r.Holds = append(r.Holds, scheduler.Hold{JobID: 3, TaskID: 3, ResourceID: 2, Units: 1, Start: 3, End: 4})
+			},
+			rule: ResourceUnits,
+		},
+		{
+			name:    "start above the system ceiling",
+			corrupt: func(r *scheduler.Result) { r.Holds[1].End = 5.2 },
+			rule:    SRPAdmission,
+		},
+		{
+			name: "run after completion",
+			corrupt: func(r *scheduler.Result) {
+				r.Schedule = append(r.Schedule, scheduler.Schedule{TaskID: 1, JobID: 1, StartTime: 10.2, EndTime: 10.5})
+			},
+			rule: NoRunAfterEnd,
+		},
+		{
+			name:    "LC job in Overrun mode",
+			corrupt: func(r *scheduler.Result) { r.ModeSwitches[0].Time = 5 },
+			rule:    NoLCInOverrun,
+		},
+		{
+			name:    "LC job in Overrun mode under early release",
+			corrupt: func(r *scheduler.Result) { r.ModeSwitches[0].Time = 5 },
+			opts:    []Option{WithConfig(&config.Config{EarlyRelease: true})},
+			rule:    NoLCInOverrun,
+			absent:  true,
+		},
+	}
+
+	result, taskSet, resourceList := cleanResult(t)
+	for _, v := range Validate(result, taskSet, resourceList) {
+		t.Errorf("clean schedule: %v", v)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corrupted, _, _ := cleanResult(t)
+			tt.corrupt(corrupted)
+
+			violations := Validate(corrupted, taskSet, resourceList, tt.opts...)
+			found := false
+			for _, v := range violations {
+				found = found || v.Rule == tt.rule
+			}
+			if found == tt.absent {
+				t.Errorf("rule %s broken = %v, want %v; violations: %v", tt.rule, found, !tt.absent, violations)
+			}
+		})
+	}
+}
+
+// removeJob removes the record and the execution intervals of the job.
+func removeJob(r *scheduler.Result, jobID int) {
+	var jobs []scheduler.JobRecord
+	for _, job := range r.Jobs {
+		if job.JobID != jobID {
+			jobs = append(jobs, job)
+		}
+	}
+	var schedule []scheduler.Schedule
+	for _, s := range r.Schedule {
+		if s.JobID != jobID {
+			schedule = append(schedule, s)
+		}
+	}
+	r.Jobs, r.Schedule = jobs, schedule
+}
+
+// findJob returns the record of the job.
+func findJob(r *scheduler.Result, jobID int) *scheduler.JobRecord {
+	for i := range r.Jobs {
+		if r.Jobs[i].JobID == jobID {
+			return &r.Jobs[i]
+		}
+	}
+	panic("no such job")
+}
+
+func TestValidateDelayedRelease(t *testing.T) {
+	// Job 2 of task 2, due at 7, is released at 8.
+	sc := &scenario.Scenario{Injections: []scenario.Injection{{Kind: scenario.DelayedRelease, TaskID: 2, Job: 2, Delay: 1}}}
+	taskSet := []*tasks.Task{
+		{ID: 1, Criticality: tasks.LC, Period: 5, Deadline: 5, WCET1: 1, PreemptionLevel: 1},
+		{ID: 2, Criticality: tasks.HC, Period: 7, Deadline: 7, WCET1: 2, WCET2: 1, PreemptionLevel: 2},
+	}
+	cfg := &config.Config{SimulateTime: 20, Quiet: true}
+	result, err := scheduler.RunScheduler(cfg, taskSet, nil, scheduler.WithScenario(sc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range Validate(result, taskSet, nil, WithScenario(sc)) {
+		t.Errorf("delayed release: %v", v)
+	}
+	if violations := Validate(result, taskSet, nil); len(violations) == 0 {
+		t.Error("delayed release accepted without its scenario")
+	}
+	result.Shifted = nil
+	if violations := Validate(result, taskSet, nil, WithScenario(sc)); len(violations) == 0 {
+		t.Error("delayed release accepted without its shifted release record")
+	}
+}